@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
+	"reservation-platform-sample/internal/api/handlers"
 	"reservation-platform-sample/internal/api/routes"
 	"reservation-platform-sample/internal/config"
 	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/cache"
+	"reservation-platform-sample/internal/services/payment"
+	"reservation-platform-sample/internal/services/reminder"
 )
 
 func main() {
@@ -40,6 +46,41 @@ func main() {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	// Index salons created before the search index or edited in the database directly
+	if _, err := handlers.ReindexStaleSalons(); err != nil {
+		log.Fatal("Failed to backfill search index:", err)
+	}
+
+	// Response cache (shared in Redis when REDIS_URL is set, in process otherwise)
+	responseCache, err := cache.Open(cfg.RedisURL)
+	if err != nil {
+		log.Fatal("Failed to connect to the cache:", err)
+	}
+	handlers.UseCache(responseCache)
+
+	// Deposits and prepayments (the local fake gateway until a provider is configured)
+	handlers.UsePaymentGateway(payment.NewFakeGateway([]byte(cfg.PaymentWebhookSecret)))
+	go handlers.RunRefundSettler(context.Background(), time.Minute)
+
+	// Appointment reminders
+	offsets, err := reminder.ParseOffsets(cfg.ReminderOffsets)
+	if err != nil {
+		log.Fatal("Invalid REMINDER_OFFSETS:", err)
+	}
+	go handlers.RunReminderScheduler(context.Background(), offsets, time.Minute)
+
+	// Outgoing webhooks from the transactional outbox
+	go handlers.RunWebhookDispatcher(context.Background(), 5*time.Second)
+
+	// Live availability and activity streams
+	go handlers.RunLiveUpdates(context.Background(), cfg.DatabaseURL)
+
+	// Search index follows staff and service changes
+	go handlers.RunSearchIndexer(context.Background(), time.Minute)
+
+	// Busy times from the stylists' external calendars
+	go handlers.RunCalendarImporter(context.Background(), 15*time.Minute)
+
 	// Route configuration
 	r := routes.SetupRoutes()
 
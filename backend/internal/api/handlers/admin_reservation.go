@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/notification"
+	"reservation-platform-sample/internal/services/pricing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Sort orders accepted by the salon reservation list
+var reservationSortColumns = map[string]sortKey{
+	"start_time":   {Expr: "start_time"},
+	"-start_time":  {Expr: "start_time", Desc: true},
+	"created_at":   {Expr: "created_at"},
+	"-created_at":  {Expr: "created_at", Desc: true},
+	"total_price":  {Expr: "total_price"},
+	"-total_price": {Expr: "total_price", Desc: true},
+}
+
+type AdminReservationRequest struct {
+	UserID         *uint     `json:"user_id"` // Existing customer; guest fields are used when omitted
+	GuestName      string    `json:"guest_name"`
+	GuestEmail     string    `json:"guest_email" binding:"omitempty,email"`
+	GuestPhone     string    `json:"guest_phone"`
+	StaffID        uint      `json:"staff_id" binding:"required"`
+	ServiceID      uint      `json:"service_id" binding:"required"`
+	StartTime      time.Time `json:"start_time" binding:"required"`
+	Notes          string    `json:"notes"`
+	CouponCode     string    `json:"coupon_code"`
+	GiftCardCode   string    `json:"gift_card_code"`
+	TotalPrice     *int      `json:"total_price" binding:"omitempty,min=0"` // Overrides the computed price
+	Override       bool      `json:"override"`                              // Skip booking rules (past time, double booking)
+	OverrideReason string    `json:"override_reason"`
+}
+
+// GetSalonReservations Get a salon's reservations with filtering, sorting and pagination
+func GetSalonReservations(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, ok := reservationSortColumns[c.DefaultQuery("sort", "start_time")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort"})
+		return
+	}
+
+	query := database.DB.Model(&models.Reservation{}).Where("salon_id = ?", salonID)
+
+	// Filter conditions
+	if from := c.Query("from"); from != "" {
+		parsedDate, err := time.ParseInLocation("2006-01-02", from, salonLocation)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return
+		}
+		query = query.Where("start_time >= ?", parsedDate)
+	}
+	if to := c.Query("to"); to != "" {
+		parsedDate, err := time.ParseInLocation("2006-01-02", to, salonLocation)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+		query = query.Where("start_time < ?", dayRange(parsedDate).End)
+	}
+	if staffID := c.Query("staff_id"); staffID != "" {
+		query = query.Where("staff_id = ?", staffID)
+	}
+	if serviceID := c.Query("service_id"); serviceID != "" {
+		query = query.Where("service_id = ?", serviceID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status IN ?", strings.Split(status, ","))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reservations"})
+		return
+	}
+
+	query = query.Preload("User").Preload("Staff").Preload("Service")
+	page, err := fetchPage(query, []sortKey{order}, "id", req, func(reservation models.Reservation) uint { return reservation.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reservations"})
+		return
+	}
+	page.Total = &total
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreateSalonReservation Create a reservation on behalf of a customer (e.g. phone-in)
+func CreateSalonReservation(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req AdminReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.UserID == nil && (req.GuestName == "" || req.GuestPhone == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id or guest_name and guest_phone are required"})
+		return
+	}
+	if req.Override && req.OverrideReason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "override_reason is required when overriding booking rules"})
+		return
+	}
+	if req.UserID != nil {
+		var user models.User
+		if err := database.DB.First(&user, *req.UserID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user not found"})
+			return
+		}
+	}
+
+	reservation := models.Reservation{
+		SalonID:         salonID,
+		StaffID:         req.StaffID,
+		UserID:          req.UserID,
+		ServiceID:       req.ServiceID,
+		ReservationDate: dayRange(req.StartTime.In(salonLocation)).Start,
+		StartTime:       req.StartTime,
+		Notes:           req.Notes,
+		GuestName:       req.GuestName,
+		GuestEmail:      req.GuestEmail,
+		GuestPhone:      req.GuestPhone,
+		CouponCode:      req.CouponCode,
+		GiftCardCode:    req.GiftCardCode,
+	}
+
+	if err := prepareReservation(&reservation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !req.Override {
+		if err := checkBookingRules(&reservation); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	redemptions, err := priceReservation(&reservation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// A manual price is recorded as an adjustment on top of the computed one
+	if req.TotalPrice != nil && *req.TotalPrice != reservation.TotalPrice {
+		reservation.PriceBreakdown = append(reservation.PriceBreakdown, models.PriceLine{
+			Type:   pricing.LineAdjustment,
+			Label:  "Manual price",
+			Amount: *req.TotalPrice - reservation.TotalPrice,
+		})
+		reservation.TotalPrice = *req.TotalPrice
+	}
+	if err := applyGiftCard(&reservation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorID := c.GetUint("userID")
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Overrides may double-book on purpose
+		if !req.Override {
+			if err := lockStaffSchedule(tx, &reservation); err != nil {
+				return err
+			}
+		}
+		if err := insertReservation(tx, &reservation, redemptions, nil); err != nil {
+			return err
+		}
+
+		audit := models.ReservationAudit{
+			ReservationID: reservation.ID,
+			ActorUserID:   actorID,
+			Action:        "created_on_behalf",
+			Reason:        req.OverrideReason,
+		}
+		if req.Override {
+			audit.Action = "rule_override"
+		}
+		return tx.Create(&audit).Error
+	})
+	var rejected *bookingError
+	if errors.As(err, &rejected) || errors.Is(err, errPromotionUnavailable) || errors.Is(err, errGiftCardUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reservation"})
+		return
+	}
+
+	database.DB.Preload("User").Preload("Staff").Preload("Service").First(&reservation, reservation.ID)
+
+	go notifyReservation(notification.EventReservationCreated, reservation)
+
+	c.JSON(http.StatusCreated, reservation)
+}
+
+// GetReservationAudits Get the audit trail of a salon reservation
+func GetReservationAudits(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var reservation models.Reservation
+	if err := database.DB.Where("id = ? AND salon_id = ?", c.Param("reservation_id"), salonID).First(&reservation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.ReservationAudit{}).Where("reservation_id = ?", reservation.ID)
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at"}}, "id", req, func(audit models.ReservationAudit) uint { return audit.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// authorizeSalonAdmin Check that the admin may manage the salon in the :id path parameter
+func authorizeSalonAdmin(c *gin.Context) (uint, bool) {
+	salonID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid salon ID"})
+		return 0, false
+	}
+
+	if !canManageSalon(c, uint(salonID)) {
+		return 0, false
+	}
+
+	return uint(salonID), true
+}
+
+// canManageSalon Check that the admin may manage the salon
+func canManageSalon(c *gin.Context, salonID uint) bool {
+	var user models.User
+	if err := database.DB.First(&user, c.GetUint("userID")).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return false
+	}
+
+	// Salon admins only manage their own salon; platform admins manage all salons
+	if user.SalonID != nil && *user.SalonID != salonID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return false
+	}
+
+	return true
+}
+
+// requirePlatformAdmin Check that the admin is not limited to a single salon
+func requirePlatformAdmin(c *gin.Context) bool {
+	var user models.User
+	if err := database.DB.First(&user, c.GetUint("userID")).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return false
+	}
+
+	if user.SalonID != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		return false
+	}
+
+	return true
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type LoginRequest struct {
@@ -24,6 +25,10 @@ type RegisterRequest struct {
 	Phone    string `json:"phone"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 type AuthResponse struct {
 	Token string      `json:"token"`
 	User  models.User `json:"user"`
@@ -66,6 +71,14 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// Issue email verification token (guest reservations are merged once the email is verified)
+	verificationToken, err := generateEmailVerificationToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	notifyEmailVerification(user, verificationToken)
+
 	// Remove password hash
 	user.PasswordHash = ""
 
@@ -111,6 +124,56 @@ func Login(c *gin.Context) {
 	})
 }
 
+// VerifyEmail Mark the user's email as verified and merge their guest reservations
+func VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := parseScopedToken(req.Token, tokenPurposeVerifyEmail)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	userID, _ := claims["verify_user_id"].(float64)
+	email, _ := claims["email"].(string)
+
+	var user models.User
+	if err := database.DB.First(&user, uint(userID)).Error; err != nil || user.Email != email {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var merged int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if user.EmailVerifiedAt == nil {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			if err := tx.Save(&user).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&models.Reservation{}).
+			Where("user_id IS NULL AND LOWER(guest_email) = LOWER(?)", user.Email).
+			Update("user_id", user.ID)
+		merged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Email verified successfully",
+		"merged_reservations": merged,
+	})
+}
+
 // GetProfile Get user information
 func GetProfile(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -141,3 +204,16 @@ func generateJWT(userID uint) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
+
+// generateEmailVerificationToken Generate email verification token
+func generateEmailVerificationToken(user models.User) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose":        tokenPurposeVerifyEmail,
+		"verify_user_id": user.ID,
+		"email":          user.Email,
+		"exp":            time.Now().Add(time.Hour * 24 * 3).Unix(), // Valid for 3 days
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
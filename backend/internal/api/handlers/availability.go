@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+)
+
+// Slot generation settings (salon local time)
+const (
+	nextSlotSearchDays  = 14
+	slotDayStartHour    = 9
+	slotDayEndHour      = 18
+	slotIntervalMinutes = 60
+	defaultSlotMinutes  = 60
+)
+
+// salonLocation Time zone the salons operate in
+var salonLocation = time.FixedZone("JST", 9*60*60)
+
+// timeRange Half-open time range [Start, End)
+type timeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// overlaps Check whether two ranges share any instant
+func (r timeRange) overlaps(other timeRange) bool {
+	return r.Start.Before(other.End) && other.Start.Before(r.End)
+}
+
+// dayRange Whole day containing the given date in salon local time
+func dayRange(date time.Time) timeRange {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, salonLocation)
+	return timeRange{Start: start, End: start.AddDate(0, 0, 1)}
+}
+
+// applyServicePhases Derive the end time and processing window of a reservation from its service
+func applyServicePhases(reservation *models.Reservation, service models.Service) {
+	reservation.EndTime = reservation.StartTime.Add(time.Duration(service.DurationMinutes) * time.Minute)
+	reservation.ProcessingStartTime = nil
+	reservation.ProcessingEndTime = nil
+
+	if service.ProcessingMinutes > 0 {
+		processingStart := reservation.StartTime.Add(time.Duration(service.ProcessingOffsetMinutes) * time.Minute)
+		processingEnd := processingStart.Add(time.Duration(service.ProcessingMinutes) * time.Minute)
+		reservation.ProcessingStartTime = &processingStart
+		reservation.ProcessingEndTime = &processingEnd
+	}
+}
+
+// applyBuffers Copy the effective buffer times onto a reservation.
+// Each level (salon, staff, service) sets a minimum, so the largest value wins.
+func applyBuffers(reservation *models.Reservation, salon models.Salon, staff models.Staff, service models.Service) {
+	reservation.BufferBeforeMinutes = max(salon.BufferBeforeMinutes, staff.BufferBeforeMinutes, service.BufferBeforeMinutes)
+	reservation.BufferAfterMinutes = max(salon.BufferAfterMinutes, staff.BufferAfterMinutes, service.BufferAfterMinutes)
+}
+
+// reservationBusyRanges Ranges during which the reservation actually occupies the staff member,
+// including the buffer before the first and after the last active phase
+func reservationBusyRanges(reservation models.Reservation) []timeRange {
+	start := reservation.StartTime.Add(-time.Duration(reservation.BufferBeforeMinutes) * time.Minute)
+	end := reservation.EndTime.Add(time.Duration(reservation.BufferAfterMinutes) * time.Minute)
+
+	if reservation.ProcessingStartTime == nil || reservation.ProcessingEndTime == nil {
+		return []timeRange{{Start: start, End: end}}
+	}
+
+	return []timeRange{
+		{Start: start, End: *reservation.ProcessingStartTime},
+		{Start: *reservation.ProcessingEndTime, End: end},
+	}
+}
+
+// loadStaffBusyRanges Load the ranges in which the staff member is busy within the window
+// (reservations, blocks and other customers' slot holds)
+func loadStaffBusyRanges(staffID uint, window timeRange, excludeReservationID uint, excludeHoldToken string) ([]timeRange, error) {
+	var reservations []models.Reservation
+	err := database.DB.Where(
+		"staff_id = ? AND id != ? AND status != 'cancelled' AND "+
+			"start_time - make_interval(mins => buffer_before_minutes) < ? AND "+
+			"end_time + make_interval(mins => buffer_after_minutes) > ?",
+		staffID,
+		excludeReservationID,
+		window.End,
+		window.Start,
+	).Find(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var busy []timeRange
+	for _, reservation := range reservations {
+		busy = append(busy, reservationBusyRanges(reservation)...)
+	}
+
+	blocks, err := loadStaffBlockRanges(staffID, window)
+	if err != nil {
+		return nil, err
+	}
+	busy = append(busy, blocks...)
+
+	holds, err := loadSlotHoldRanges(staffID, window, excludeHoldToken)
+	if err != nil {
+		return nil, err
+	}
+	busy = append(busy, holds...)
+
+	return busy, nil
+}
+
+// loadSlotHoldRanges Load the unexpired slot holds within the window, except the one being booked
+func loadSlotHoldRanges(staffID uint, window timeRange, excludeHoldToken string) ([]timeRange, error) {
+	var holds []models.SlotHold
+	err := database.DB.Where(
+		"staff_id = ? AND token != ? AND expires_at > ? AND "+
+			"start_time - make_interval(mins => buffer_before_minutes) < ? AND "+
+			"end_time + make_interval(mins => buffer_after_minutes) > ?",
+		staffID,
+		excludeHoldToken,
+		time.Now(),
+		window.End,
+		window.Start,
+	).Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]timeRange, 0, len(holds))
+	for _, hold := range holds {
+		ranges = append(ranges, timeRange{
+			Start: hold.StartTime.Add(-time.Duration(hold.BufferBeforeMinutes) * time.Minute),
+			End:   hold.EndTime.Add(time.Duration(hold.BufferAfterMinutes) * time.Minute),
+		})
+	}
+
+	return ranges, nil
+}
+
+// loadStaffBlockRanges Load the staff block occurrences within the window
+func loadStaffBlockRanges(staffID uint, window timeRange) ([]timeRange, error) {
+	var blocks []models.StaffBlock
+	err := database.DB.Where(
+		"staff_id = ? AND start_time < ? AND (end_time > ? OR (recurrence != '' AND (recurrence_until IS NULL OR recurrence_until > ?)))",
+		staffID,
+		window.End,
+		window.Start,
+		window.Start,
+	).Find(&blocks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []timeRange
+	for _, block := range blocks {
+		ranges = append(ranges, blockOccurrences(block, window)...)
+	}
+
+	return ranges, nil
+}
+
+// blockOccurrences Expand a (possibly recurring) block into its occurrences overlapping the window
+func blockOccurrences(block models.StaffBlock, window timeRange) []timeRange {
+	first := timeRange{Start: block.StartTime, End: block.EndTime}
+
+	days := 0
+	switch block.Recurrence {
+	case "daily":
+		days = 1
+	case "weekly":
+		days = 7
+	default:
+		if first.overlaps(window) {
+			return []timeRange{first}
+		}
+		return nil
+	}
+
+	// Skip whole periods that end before the window
+	skip := 0
+	if window.Start.After(first.End) {
+		skip = int(window.Start.Sub(first.End).Hours()/24) / days
+	}
+
+	var occurrences []timeRange
+	for i := skip; ; i++ {
+		occurrence := timeRange{
+			Start: first.Start.AddDate(0, 0, i*days),
+			End:   first.End.AddDate(0, 0, i*days),
+		}
+		if !occurrence.Start.Before(window.End) {
+			break
+		}
+		if block.RecurrenceUntil != nil && occurrence.Start.After(*block.RecurrenceUntil) {
+			break
+		}
+		if occurrence.overlaps(window) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	return occurrences
+}
+
+// conflicts Check whether any of the candidate ranges overlaps a busy range
+func conflicts(candidate, busy []timeRange) bool {
+	for _, c := range candidate {
+		for _, b := range busy {
+			if c.overlaps(b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextAvailableSlot Find the first available slot within the search horizon (nil when fully booked)
+func nextAvailableSlot(salonID, staffID string) (*time.Time, error) {
+	today := dayRange(time.Now().In(salonLocation)).Start
+	for i := 0; i < nextSlotSearchDays; i++ {
+		date := today.AddDate(0, 0, i)
+		slots, err := getAvailableSlots(salonID, staffID, "", date)
+		if err != nil {
+			return nil, err
+		}
+		if len(slots) == 0 {
+			continue
+		}
+
+		slot, err := time.ParseInLocation("2006-01-02 15:04", date.Format("2006-01-02")+" "+slots[0], salonLocation)
+		if err != nil {
+			return nil, err
+		}
+		return &slot, nil
+	}
+
+	return nil, nil
+}
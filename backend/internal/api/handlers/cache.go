@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseCache Cached salon and availability responses (in process until UseCache installs the configured store)
+var responseCache = cache.NewStore(cache.NewMemory(cache.DefaultMemoryEntries))
+
+// Cache lifetimes; changes made through the API invalidate entries before they expire
+const (
+	salonCacheTTL = 5 * time.Minute
+	slotsCacheTTL = time.Minute // Slots that have started drop out of today's availability within this
+)
+
+// Kinds of cached entry reported by GetCacheStats
+const (
+	cacheKindSalon     = "salon"
+	cacheKindSalonList = "salon_list"
+	cacheKindSlots     = "availability"
+)
+
+// salonListNamespace Namespace of every salon list page
+const salonListNamespace = "salons"
+
+// UseCache Cache responses in the store (Redis shared by the server instances, or in process)
+func UseCache(store *cache.Store) {
+	responseCache = store
+}
+
+// GetCacheStats Get the cache backend and its hit and miss counts since the server started
+func GetCacheStats(c *gin.Context) {
+	if !requirePlatformAdmin(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"backend": responseCache.Backend(),
+		"stats":   responseCache.Stats(),
+	})
+}
+
+// FlushCache Drop every cached response, e.g. after editing salons, staff or services in the database directly
+func FlushCache(c *gin.Context) {
+	if !requirePlatformAdmin(c) {
+		return
+	}
+
+	if err := responseCache.Flush(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to flush cache"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cache flushed successfully"})
+}
+
+// salonNamespace Namespace of a salon's detail responses
+func salonNamespace(salonID uint) string {
+	return "salon:" + strconv.FormatUint(uint64(salonID), 10)
+}
+
+// slotsNamespace Namespace of a salon's availability
+func slotsNamespace(salonID uint) string {
+	return "slots:" + strconv.FormatUint(uint64(salonID), 10)
+}
+
+// invalidateSalon Drop the cached responses showing the salon, its staff or services: its details,
+// the salon lists and its availability (failures are logged, entries then expire on their own)
+func invalidateSalon(salonID uint) {
+	if err := responseCache.Invalidate(context.Background(), salonNamespace(salonID), salonListNamespace, slotsNamespace(salonID)); err != nil {
+		log.Printf("Failed to invalidate cache of salon %d: %v", salonID, err)
+	}
+}
+
+// invalidateSlots Drop the salon's cached availability after bookings, holds, blocks or prices change
+func invalidateSlots(salonID uint) {
+	if err := responseCache.Invalidate(context.Background(), slotsNamespace(salonID)); err != nil {
+		log.Printf("Failed to invalidate cached availability of salon %d: %v", salonID, err)
+	}
+}
+
+// invalidateStaffSlots Drop the cached availability of the staff member's salon
+func invalidateStaffSlots(staffID uint) {
+	var staff models.Staff
+	if err := database.DB.Select("id", "salon_id").First(&staff, staffID).Error; err != nil {
+		log.Printf("Failed to invalidate cached availability of staff %d: %v", staffID, err)
+		return
+	}
+	invalidateSlots(staff.SalonID)
+}
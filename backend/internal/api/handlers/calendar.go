@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/ical"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// calendarBaseURL Public API URL used in subscription feed links
+var calendarBaseURL = "http://localhost:8080/api" // Should be obtained from environment variables
+
+// calendarUIDDomain Domain part of event UIDs; must never change once feeds are published
+var calendarUIDDomain = "reservation-platform-sample" // Should be obtained from environment variables
+
+const (
+	calendarProdID          = "-//reservation-platform-sample//Reservations//EN"
+	calendarRefreshInterval = time.Hour
+	calendarFeedHistory     = 30 * 24 * time.Hour // Past reservations kept in feeds
+)
+
+// GetReservationICS Download one of the user's reservations as an .ics file
+func GetReservationICS(c *gin.Context) {
+	var reservation models.Reservation
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), c.GetUint("userID")).
+		Preload("Salon").Preload("Staff").Preload("Service").
+		First(&reservation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	writeICSFile(c, reservation)
+}
+
+// GetGuestReservationICS Download a reservation as an .ics file from a manage link token
+func GetGuestReservationICS(c *gin.Context) {
+	reservationID, err := parseManageToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var reservation models.Reservation
+	if err := database.DB.Preload("Salon").Preload("Staff").Preload("Service").First(&reservation, reservationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	writeICSFile(c, reservation)
+}
+
+// GetMyCalendarFeed Get the user's private subscription URL of their reservations
+func GetMyCalendarFeed(c *gin.Context) {
+	respondCalendarFeed(c, "user_id", c.GetUint("userID"), false)
+}
+
+// ResetMyCalendarFeed Replace the user's subscription URL (the old one stops working)
+func ResetMyCalendarFeed(c *gin.Context) {
+	respondCalendarFeed(c, "user_id", c.GetUint("userID"), true)
+}
+
+// GetMyStaffCalendarFeed Get the calling staff member's private subscription URL of their appointments
+func GetMyStaffCalendarFeed(c *gin.Context) {
+	staff, ok := currentStaff(c)
+	if !ok {
+		return
+	}
+	respondCalendarFeed(c, "staff_id", staff.ID, false)
+}
+
+// ResetMyStaffCalendarFeed Replace the calling staff member's subscription URL
+func ResetMyStaffCalendarFeed(c *gin.Context) {
+	staff, ok := currentStaff(c)
+	if !ok {
+		return
+	}
+	respondCalendarFeed(c, "staff_id", staff.ID, true)
+}
+
+// GetCalendarFeed Subscription feed identified by its private token (the token is the secret)
+func GetCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	var feed models.CalendarFeed
+	if err := database.DB.Where("token = ?", token).First(&feed).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	// Recent past and cancelled reservations stay in the feed so clients pick up the changes
+	query := database.DB.Where("start_time >= ?", time.Now().Add(-calendarFeedHistory)).
+		Preload("Salon").Preload("Staff").Preload("Service").Preload("User").
+		Order("start_time")
+	name := "Reservations"
+	forStaff := feed.StaffID != nil
+	if forStaff {
+		var staff models.Staff
+		if err := database.DB.First(&staff, *feed.StaffID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+			return
+		}
+		query = query.Where("staff_id = ?", staff.ID)
+		name = staff.Name + " appointments"
+	} else {
+		query = query.Where("user_id = ?", *feed.UserID)
+	}
+
+	var reservations []models.Reservation
+	if err := query.Find(&reservations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reservations"})
+		return
+	}
+
+	calendar := ical.Calendar{
+		ProdID:          calendarProdID,
+		Name:            name,
+		Location:        salonLocation,
+		RefreshInterval: calendarRefreshInterval,
+		Events:          make([]ical.Event, 0, len(reservations)),
+	}
+	for _, reservation := range reservations {
+		calendar.Events = append(calendar.Events, reservationEvent(reservation, forStaff))
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, ical.ContentType, []byte(calendar.String()))
+}
+
+// respondCalendarFeed Respond with the feed URLs of an owner, creating or resetting the token
+func respondCalendarFeed(c *gin.Context, ownerColumn string, ownerID uint, reset bool) {
+	feed, err := issueCalendarFeed(ownerColumn, ownerID, reset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue calendar feed"})
+		return
+	}
+
+	url := calendarBaseURL + "/calendar/" + feed.Token + ".ics"
+	c.JSON(http.StatusOK, gin.H{
+		"url":        url,
+		"webcal_url": "webcal://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://"),
+	})
+}
+
+// issueCalendarFeed Feed of a customer (user_id) or stylist (staff_id), created on first use
+func issueCalendarFeed(ownerColumn string, ownerID uint, reset bool) (models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := database.DB.Where(ownerColumn+" = ?", ownerID).First(&feed).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return feed, err
+	}
+	if err == nil && !reset {
+		return feed, nil
+	}
+
+	token, err := ical.NewFeedToken()
+	if err != nil {
+		return feed, err
+	}
+
+	if feed.ID != 0 {
+		feed.Token = token
+		return feed, database.DB.Model(&feed).Update("token", token).Error
+	}
+
+	feed.Token = token
+	if ownerColumn == "staff_id" {
+		feed.StaffID = &ownerID
+	} else {
+		feed.UserID = &ownerID
+	}
+	// A concurrent request may have created the feed first; use whichever was stored
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&feed).Error; err != nil {
+		return feed, err
+	}
+	err = database.DB.Where(ownerColumn+" = ?", ownerID).First(&feed).Error
+	return feed, err
+}
+
+// writeICSFile Respond with a single reservation as an .ics attachment
+func writeICSFile(c *gin.Context, reservation models.Reservation) {
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="reservation-%d.ics"`, reservation.ID))
+	c.Data(http.StatusOK, ical.ContentType, []byte(reservationCalendar(reservation)))
+}
+
+// reservationCalendar Calendar file of a single reservation (Salon, Staff and Service preloaded)
+func reservationCalendar(reservation models.Reservation) string {
+	return ical.Calendar{
+		ProdID:   calendarProdID,
+		Location: salonLocation,
+		Events:   []ical.Event{reservationEvent(reservation, false)},
+	}.String()
+}
+
+// reservationEvent Calendar event of a reservation, as seen by the customer or the stylist.
+// The UID stays the same for the life of the reservation; CalendarSequence orders its versions.
+func reservationEvent(reservation models.Reservation, forStaff bool) ical.Event {
+	var salonName, address, staffName, serviceName string
+	if reservation.Salon != nil {
+		salonName, address = reservation.Salon.Name, reservation.Salon.Address
+	}
+	if reservation.Staff != nil {
+		staffName = reservation.Staff.Name
+	}
+	if reservation.Service != nil {
+		serviceName = reservation.Service.Name
+	}
+
+	event := ical.Event{
+		UID:          fmt.Sprintf("reservation-%d@%s", reservation.ID, calendarUIDDomain),
+		Sequence:     reservation.CalendarSequence,
+		Start:        reservation.StartTime,
+		End:          reservation.EndTime,
+		Location:     address,
+		Status:       ical.StatusConfirmed,
+		Created:      reservation.CreatedAt,
+		LastModified: reservation.UpdatedAt,
+	}
+	if reservation.Status == "cancelled" {
+		event.Status = ical.StatusCancelled
+	}
+
+	if forStaff {
+		customerName := reservation.GuestName
+		if reservation.User != nil {
+			customerName = reservation.User.Name
+		}
+		event.Summary = serviceName + " - " + customerName
+		event.Description = reservation.Notes
+		return event
+	}
+
+	event.Summary = serviceName + " @ " + salonName
+	event.Description = fmt.Sprintf("Stylist: %s\nTotal: ¥%d", staffName, reservation.TotalPrice)
+	return event
+}
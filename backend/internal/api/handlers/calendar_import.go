@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/ical"
+	"reservation-platform-sample/internal/services/safehttp"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// calendarFileDir Directory holding the .ics files that file:// calendar sources may name (local testing)
+var calendarFileDir = "calendars" // Should be obtained from environment variables
+
+const (
+	staffBlockSourceICS      = "ics"
+	calendarImportHorizon    = 90 * 24 * time.Hour // Busy times further ahead are not imported
+	calendarImportMaxBytes   = 5 << 20
+	calendarImportBlockLabel = "External calendar" // Imported blocks don't copy event titles from personal calendars
+	calendarUnsupportedShown = 3                   // UIDs of unsupported recurring events listed in last_error
+)
+
+// calendarHTTPClient Client fetching calendar sources; it only connects to public addresses
+var calendarHTTPClient = safehttp.NewClient(30 * time.Second)
+
+// Import errors that are safe to show to admins as they are
+var (
+	errCalendarStatus         = errors.New("unexpected status")
+	errLocalCalendarsDisabled = errors.New("local calendar files are disabled")
+	errCalendarFileName       = errors.New("file sources must name a file in the calendar directory")
+)
+
+type CalendarSourceRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// GetStaffCalendarSource Get the staff member's external calendar, its import status and reservations colliding with it
+func GetStaffCalendarSource(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	var source models.StaffCalendarSource
+	if err := database.DB.Where("staff_id = ?", staff.ID).First(&source).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar source not found"})
+		return
+	}
+
+	respondCalendarSource(c, http.StatusOK, source)
+}
+
+// PutStaffCalendarSource Set the staff member's external calendar and import it right away
+func PutStaffCalendarSource(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	var req CalendarSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var source models.StaffCalendarSource
+	if err := database.DB.Where("staff_id = ?", staff.ID).First(&source).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch calendar source"})
+		return
+	}
+	source.StaffID = staff.ID
+	source.URL = strings.TrimSpace(req.URL)
+
+	if err := validateCalendarSource(source.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Save(&source).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save calendar source"})
+		return
+	}
+
+	// A failed first import is reported in last_error and retried by the importer
+	if err := importCalendarSource(c.Request.Context(), source, time.Now()); err != nil {
+		log.Printf("Failed to import calendar of staff %d: %v", staff.ID, err)
+	}
+	database.DB.First(&source, source.ID)
+
+	respondCalendarSource(c, http.StatusOK, source)
+}
+
+// DeleteStaffCalendarSource Remove the staff member's external calendar and the busy times imported from it
+func DeleteStaffCalendarSource(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("staff_id = ?", staff.ID).Delete(&models.StaffCalendarSource{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Unscoped().Where("staff_id = ? AND source = ?", staff.ID, staffBlockSourceICS).Delete(&models.StaffBlock{}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar source not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete calendar source"})
+		return
+	}
+	invalidateSlots(staff.SalonID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar source deleted successfully"})
+}
+
+// SyncStaffCalendarSource Import the staff member's external calendar now
+func SyncStaffCalendarSource(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	var source models.StaffCalendarSource
+	if err := database.DB.Where("staff_id = ?", staff.ID).First(&source).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar source not found"})
+		return
+	}
+
+	if err := importCalendarSource(c.Request.Context(), source, time.Now()); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to import calendar: " + calendarSourceError(err)})
+		return
+	}
+	database.DB.First(&source, source.ID)
+
+	respondCalendarSource(c, http.StatusOK, source)
+}
+
+// RunCalendarImporter Import each staff calendar source once per interval until the context ends.
+// Several server instances can run it at once; each import is claimed by one of them.
+func RunCalendarImporter(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := importDueCalendars(ctx, interval, time.Now()); err != nil {
+			log.Printf("Failed to import staff calendars: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// importDueCalendars Import the sources not attempted within the interval
+func importDueCalendars(ctx context.Context, interval time.Duration, now time.Time) error {
+	var sources []models.StaffCalendarSource
+	due := now.Add(-interval)
+	if err := database.DB.Where("attempt_at IS NULL OR attempt_at <= ?", due).Order("id").Find(&sources).Error; err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		// Claim the source by moving its attempt time; another instance may have claimed it first
+		result := database.DB.Model(&models.StaffCalendarSource{}).
+			Where("id = ? AND (attempt_at IS NULL OR attempt_at <= ?)", source.ID, due).
+			Update("attempt_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if err := importCalendarSource(ctx, source, now); err != nil {
+			log.Printf("Failed to import calendar of staff %d: %v", source.StaffID, err)
+		}
+	}
+	return nil
+}
+
+// importCalendarSource Replace the staff member's imported blocks with the source's busy times.
+// When the source can't be read the previous blocks are kept and the error is recorded. Recurring
+// events that can't be expanded are imported as one occurrence and reported in last_error.
+func importCalendarSource(ctx context.Context, source models.StaffCalendarSource, now time.Time) error {
+	busy, unsupported, err := fetchBusyTimes(ctx, source.URL, now)
+	if err != nil {
+		database.DB.Model(&source).Updates(map[string]interface{}{
+			"attempt_at": now,
+			"last_error": calendarSourceError(err),
+		})
+		return err
+	}
+
+	lastError := ""
+	if len(unsupported) > 0 {
+		shown := unsupported[:min(len(unsupported), calendarUnsupportedShown)]
+		lastError = fmt.Sprintf("%d recurring events use repeat rules that can't be imported, only their first occurrence blocks time: %s",
+			len(unsupported), strings.Join(shown, ", "))
+	}
+
+	blocks := make([]models.StaffBlock, 0, len(busy))
+	for _, b := range busy {
+		blocks = append(blocks, models.StaffBlock{
+			StaffID:     source.StaffID,
+			StartTime:   b.Start,
+			EndTime:     b.End,
+			AllDay:      b.AllDay,
+			Reason:      calendarImportBlockLabel,
+			Source:      staffBlockSourceICS,
+			ExternalUID: b.UID,
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Concurrent imports of the same source replace the blocks one after the other
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.StaffCalendarSource{}, source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("staff_id = ? AND source = ?", source.StaffID, staffBlockSourceICS).Delete(&models.StaffBlock{}).Error; err != nil {
+			return err
+		}
+		if len(blocks) > 0 {
+			if err := tx.CreateInBatches(blocks, 200).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&source).Updates(map[string]interface{}{
+			"synced_at":   now,
+			"attempt_at":  now,
+			"last_error":  lastError,
+			"busy_blocks": len(blocks),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	invalidateStaffSlots(source.StaffID)
+	return nil
+}
+
+// fetchBusyTimes Read a calendar source and return its busy times from today to the import horizon,
+// with the UIDs of the recurring events that could not be expanded
+func fetchBusyTimes(ctx context.Context, source string, now time.Time) ([]ical.Busy, []string, error) {
+	body, err := openCalendarSource(ctx, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	from := dayRange(now.In(salonLocation)).Start
+	return ical.ParseBusy(io.LimitReader(body, calendarImportMaxBytes), salonLocation, from, now.Add(calendarImportHorizon))
+}
+
+// openCalendarSource Open an http(s)/webcal URL, or a file:// URL naming a file in calendarFileDir
+func openCalendarSource(ctx context.Context, source string) (io.ReadCloser, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "file":
+		if calendarFileDir == "" {
+			return nil, errLocalCalendarsDisabled
+		}
+		name, err := calendarFileName(u)
+		if err != nil {
+			return nil, err
+		}
+		return os.Open(filepath.Join(calendarFileDir, name))
+	case "webcal":
+		u.Scheme = "https"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := calendarHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w %d", errCalendarStatus, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// calendarFileName Name of the file a file:// source reads. Only files directly inside calendarFileDir can be read.
+func calendarFileName(u *url.URL) (string, error) {
+	name := strings.Trim(u.Host+u.Path, "/")
+	if strings.Contains(name, "..") || strings.ContainsRune(name, '\\') || name != path.Base(name) {
+		return "", errCalendarFileName
+	}
+	return name, nil
+}
+
+// validateCalendarSource Check that a source URL can be imported (remote calendars must be public)
+func validateCalendarSource(source string) error {
+	if !ical.ValidSource(source) {
+		return errors.New("url must be an http, https, webcal or file URL")
+	}
+
+	u, _ := url.Parse(source)
+	switch u.Scheme {
+	case "file":
+		_, err := calendarFileName(u)
+		return err
+	case "webcal":
+		u.Scheme = "https"
+	}
+	return safehttp.CheckURL(u.String())
+}
+
+// calendarSourceError Import error shown to admins. Other errors (network, parsing) could reveal
+// how internal names resolve or what a host answers, so they are only logged.
+func calendarSourceError(err error) string {
+	switch {
+	case errors.Is(err, safehttp.ErrForbiddenAddress):
+		return "url must point to a public address"
+	case errors.Is(err, fs.ErrNotExist):
+		return "calendar file not found"
+	case errors.Is(err, errCalendarStatus), errors.Is(err, errLocalCalendarsDisabled),
+		errors.Is(err, errCalendarFileName), errors.Is(err, ical.ErrNotCalendar):
+		return err.Error()
+	default:
+		return "calendar could not be read"
+	}
+}
+
+// respondCalendarSource Respond with a source and the upcoming reservations colliding with its busy times
+func respondCalendarSource(c *gin.Context, status int, source models.StaffCalendarSource) {
+	conflicting, err := findCalendarSourceConflicts(source.StaffID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conflicting reservations"})
+		return
+	}
+
+	c.JSON(status, gin.H{
+		"source":    source,
+		"conflicts": conflicting,
+	})
+}
+
+// findCalendarSourceConflicts Upcoming confirmed reservations overlapping imported busy times
+func findCalendarSourceConflicts(staffID uint) ([]models.Reservation, error) {
+	window := timeRange{Start: time.Now(), End: time.Now().Add(calendarImportHorizon)}
+
+	var blocks []models.StaffBlock
+	if err := database.DB.Where("staff_id = ? AND source = ? AND end_time > ?", staffID, staffBlockSourceICS, window.Start).
+		Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	conflicting := []models.Reservation{}
+	if len(blocks) == 0 {
+		return conflicting, nil
+	}
+	busy := make([]timeRange, len(blocks))
+	for i, block := range blocks {
+		busy[i] = timeRange{Start: block.StartTime, End: block.EndTime}
+	}
+
+	var reservations []models.Reservation
+	if err := database.DB.Where("staff_id = ? AND status = 'confirmed' AND end_time > ? AND start_time < ?", staffID, window.Start, window.End).
+		Preload("User").Preload("Service").Order("start_time").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	for _, reservation := range reservations {
+		if conflicts(reservationBusyRanges(reservation), busy) {
+			conflicting = append(conflicting, reservation)
+		}
+	}
+
+	return conflicting, nil
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCalendarFileName(t *testing.T) {
+	tests := []struct {
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{"file://example.ics", "example.ics", false},
+		{"file:///example.ics", "example.ics", false},
+		{"file://../example.ics", "", true},
+		{"file://..", "", true},
+		{"file:///%2e%2e/etc/passwd", "", true},
+		{"file:///etc/passwd", "", true},
+		{"file://sub/example.ics", "", true},
+		{"file:///sub%5Cexample.ics", "", true},
+	}
+
+	for _, tt := range tests {
+		u, err := url.Parse(tt.source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := calendarFileName(u)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("calendarFileName(%q) = %q, %v, want %q (error %v)", tt.source, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+type FavoriteSalonResponse struct {
+	models.Salon
+	NextAvailableSlot *time.Time `json:"next_available_slot,omitempty"`
+}
+
+type FavoriteStaffResponse struct {
+	models.Staff
+	NextAvailableSlot *time.Time `json:"next_available_slot,omitempty"`
+}
+
+// GetFavoriteSalons Get the user's favorite salons, most recently added first.
+// The next available slot of each is only searched with ?include=next_slot, as it scans up to two weeks per favorite.
+func GetFavoriteSalons(c *gin.Context) {
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Deleted salons are left out
+	query := database.DB.Model(&models.FavoriteSalon{}).
+		Where("user_id = ?", c.GetUint("userID")).
+		Where("EXISTS (SELECT 1 FROM salons s WHERE s.id = favorite_salons.salon_id AND s.deleted_at IS NULL)").
+		Preload("Salon")
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at", Desc: true}}, "id", req, func(favorite models.FavoriteSalon) uint { return favorite.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favorites"})
+		return
+	}
+
+	salons, err := favoriteSalonResponses(page.Data, c.Query("include") == "next_slot")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch next available slots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, Page[FavoriteSalonResponse]{Data: salons, NextCursor: page.NextCursor, HasMore: page.HasMore})
+}
+
+// GetFavoriteStaff Get the user's favorite stylists, most recently added first.
+// The next available slot of each is only searched with ?include=next_slot.
+func GetFavoriteStaff(c *gin.Context) {
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Deleted staff are left out
+	query := database.DB.Model(&models.FavoriteStaff{}).
+		Where("user_id = ?", c.GetUint("userID")).
+		Where("EXISTS (SELECT 1 FROM staffs st WHERE st.id = favorite_staffs.staff_id AND st.deleted_at IS NULL)").
+		Preload("Staff")
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at", Desc: true}}, "id", req, func(favorite models.FavoriteStaff) uint { return favorite.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favorites"})
+		return
+	}
+
+	staff, err := favoriteStaffResponses(page.Data, c.Query("include") == "next_slot")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch next available slots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, Page[FavoriteStaffResponse]{Data: staff, NextCursor: page.NextCursor, HasMore: page.HasMore})
+}
+
+// favoriteSalonResponses Favorite salons with their next available slot when requested.
+// Deleted salons are skipped (the preloaded relation is nil).
+func favoriteSalonResponses(favorites []models.FavoriteSalon, includeNextSlot bool) ([]FavoriteSalonResponse, error) {
+	salons := []FavoriteSalonResponse{}
+	for _, favorite := range favorites {
+		if favorite.Salon == nil {
+			continue
+		}
+		isFavorite := true
+		favorite.Salon.IsFavorite = &isFavorite
+		response := FavoriteSalonResponse{Salon: *favorite.Salon}
+		if includeNextSlot {
+			slot, err := nextAvailableSlot(strconv.Itoa(int(favorite.SalonID)), "")
+			if err != nil {
+				return nil, err
+			}
+			response.NextAvailableSlot = slot
+		}
+		salons = append(salons, response)
+	}
+	return salons, nil
+}
+
+// favoriteStaffResponses Favorite stylists with their next available slot when requested.
+// Deleted staff are skipped (the preloaded relation is nil).
+func favoriteStaffResponses(favorites []models.FavoriteStaff, includeNextSlot bool) ([]FavoriteStaffResponse, error) {
+	staff := []FavoriteStaffResponse{}
+	for _, favorite := range favorites {
+		if favorite.Staff == nil {
+			continue
+		}
+		response := FavoriteStaffResponse{Staff: *favorite.Staff}
+		if includeNextSlot {
+			slot, err := nextAvailableSlot(strconv.Itoa(int(favorite.Staff.SalonID)), strconv.Itoa(int(favorite.StaffID)))
+			if err != nil {
+				return nil, err
+			}
+			response.NextAvailableSlot = slot
+		}
+		staff = append(staff, response)
+	}
+	return staff, nil
+}
+
+// AddFavoriteSalon Add a salon to favorites
+func AddFavoriteSalon(c *gin.Context) {
+	var salon models.Salon
+	if err := database.DB.First(&salon, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Salon not found"})
+		return
+	}
+
+	favorite := models.FavoriteSalon{UserID: c.GetUint("userID"), SalonID: salon.ID}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add favorite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Salon added to favorites"})
+}
+
+// RemoveFavoriteSalon Remove a salon from favorites
+func RemoveFavoriteSalon(c *gin.Context) {
+	if err := database.DB.Where("user_id = ? AND salon_id = ?", c.GetUint("userID"), c.Param("id")).Delete(&models.FavoriteSalon{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove favorite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Salon removed from favorites"})
+}
+
+// AddFavoriteStaff Add a stylist to favorites
+func AddFavoriteStaff(c *gin.Context) {
+	var staff models.Staff
+	if err := database.DB.First(&staff, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
+		return
+	}
+
+	favorite := models.FavoriteStaff{UserID: c.GetUint("userID"), StaffID: staff.ID}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add favorite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Staff added to favorites"})
+}
+
+// RemoveFavoriteStaff Remove a stylist from favorites
+func RemoveFavoriteStaff(c *gin.Context) {
+	if err := database.DB.Where("user_id = ? AND staff_id = ?", c.GetUint("userID"), c.Param("id")).Delete(&models.FavoriteStaff{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove favorite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Staff removed from favorites"})
+}
+
+// markFavoriteSalons Set IsFavorite on the salons when the request is authenticated
+func markFavoriteSalons(c *gin.Context, salons []models.Salon) error {
+	userID, exists := c.Get("userID")
+	if !exists || len(salons) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(salons))
+	for i, salon := range salons {
+		ids[i] = salon.ID
+	}
+
+	var favoriteIDs []uint
+	if err := database.DB.Model(&models.FavoriteSalon{}).
+		Where("user_id = ? AND salon_id IN ?", userID, ids).
+		Pluck("salon_id", &favoriteIDs).Error; err != nil {
+		return err
+	}
+
+	favorites := make(map[uint]bool, len(favoriteIDs))
+	for _, id := range favoriteIDs {
+		favorites[id] = true
+	}
+
+	for i := range salons {
+		isFavorite := favorites[salons[i].ID]
+		salons[i].IsFavorite = &isFavorite
+	}
+
+	return nil
+}
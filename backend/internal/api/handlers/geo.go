@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Location search settings
+const (
+	kmPerDegreeLat     = 111.045
+	defaultRadiusKm    = 5.0
+	maxRadiusKm        = 50.0
+	distanceSQLPattern = "(2 * 6371 * ASIN(SQRT(POWER(SIN(RADIANS(salons.latitude - ?) / 2), 2) + " +
+		"COS(RADIANS(?)) * COS(RADIANS(salons.latitude)) * POWER(SIN(RADIANS(salons.longitude - ?) / 2), 2))))"
+)
+
+// boundingBox Latitude/longitude rectangle
+type boundingBox struct {
+	South float64
+	West  float64
+	North float64
+	East  float64
+}
+
+// geoQuery Location conditions of a salon search
+type geoQuery struct {
+	Center   bool // Whether Lat/Lng/RadiusKm are set
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+	Box      *boundingBox
+}
+
+// parseGeoQuery Parse lat, lng, radius_km and bbox (south,west,north,east) query parameters
+func parseGeoQuery(c *gin.Context) (geoQuery, error) {
+	var geo geoQuery
+
+	lat, lng := c.Query("lat"), c.Query("lng")
+	if lat != "" || lng != "" {
+		var err error
+		if geo.Lat, err = strconv.ParseFloat(lat, 64); err != nil || math.Abs(geo.Lat) > 90 {
+			return geo, errors.New("invalid lat")
+		}
+		if geo.Lng, err = strconv.ParseFloat(lng, 64); err != nil || math.Abs(geo.Lng) > 180 {
+			return geo, errors.New("invalid lng")
+		}
+
+		geo.RadiusKm = defaultRadiusKm
+		if radius := c.Query("radius_km"); radius != "" {
+			if geo.RadiusKm, err = strconv.ParseFloat(radius, 64); err != nil || geo.RadiusKm <= 0 || geo.RadiusKm > maxRadiusKm {
+				return geo, errors.New("invalid radius_km")
+			}
+		}
+		geo.Center = true
+	}
+
+	if bbox := c.Query("bbox"); bbox != "" {
+		parts := strings.Split(bbox, ",")
+		if len(parts) != 4 {
+			return geo, errors.New("invalid bbox")
+		}
+
+		var values [4]float64
+		for i, part := range parts {
+			value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return geo, errors.New("invalid bbox")
+			}
+			values[i] = value
+		}
+
+		geo.Box = &boundingBox{South: values[0], West: values[1], North: values[2], East: values[3]}
+		if geo.Box.South > geo.Box.North || geo.Box.West > geo.Box.East {
+			return geo, errors.New("invalid bbox")
+		}
+	}
+
+	return geo, nil
+}
+
+// radiusBox Bounding box enclosing the search circle, used to narrow candidates by index
+func (g geoQuery) radiusBox() boundingBox {
+	latDelta := g.RadiusKm / kmPerDegreeLat
+	lngDelta := g.RadiusKm / (kmPerDegreeLat * math.Max(math.Cos(g.Lat*math.Pi/180), 0.01))
+
+	return boundingBox{
+		South: g.Lat - latDelta,
+		West:  g.Lng - lngDelta,
+		North: g.Lat + latDelta,
+		East:  g.Lng + lngDelta,
+	}
+}
+
+// applyGeoQuery Add location conditions to a salon query
+func applyGeoQuery(query *gorm.DB, geo geoQuery) *gorm.DB {
+	if geo.Box != nil {
+		query = withinBox(query, *geo.Box)
+	}
+
+	if geo.Center {
+		// Cheap indexed box filter first, then the exact great-circle distance
+		query = withinBox(query, geo.radiusBox())
+		query = query.Where(distanceSQLPattern+" <= ?", geo.Lat, geo.Lat, geo.Lng, geo.RadiusKm)
+	}
+
+	return query
+}
+
+// withinBox Restrict a salon query to a bounding box
+func withinBox(query *gorm.DB, box boundingBox) *gorm.DB {
+	return query.Where(
+		"salons.latitude BETWEEN ? AND ? AND salons.longitude BETWEEN ? AND ?",
+		box.South, box.North, box.West, box.East,
+	)
+}
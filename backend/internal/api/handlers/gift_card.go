@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/giftcard"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// giftCardValidity Validity of a gift card issued without an expiry date
+const giftCardValidity = 365 * 24 * time.Hour
+
+var (
+	errInvalidGiftCard     = errors.New("invalid gift card code")
+	errGiftCardExpired     = errors.New("gift card has expired")
+	errGiftCardEmpty       = errors.New("gift card has no balance left")
+	errGiftCardUnavailable = errors.New("gift card balance is no longer available")
+)
+
+type IssueGiftCardRequest struct {
+	Amount         int        `json:"amount" binding:"required,min=1"`
+	ExpiresAt      *time.Time `json:"expires_at"` // Defaults to one year after issue
+	PurchaserName  string     `json:"purchaser_name"`
+	RecipientName  string     `json:"recipient_name"`
+	RecipientEmail string     `json:"recipient_email" binding:"omitempty,email"`
+	Note           string     `json:"note"`
+}
+
+// GetGiftCardBalance Look up a gift card's balance by code
+func GetGiftCardBalance(c *gin.Context) {
+	var card models.GiftCard
+	if err := database.DB.Where("code = ?", giftcard.NormalizeCode(c.Param("code"))).Preload("Salon").First(&card).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gift card not found"})
+		return
+	}
+
+	response := gin.H{
+		"code":       card.Code,
+		"balance":    card.Balance,
+		"expires_at": card.ExpiresAt,
+		"expired":    card.Expired(time.Now()),
+		"salon_id":   card.SalonID,
+	}
+	if card.Salon != nil {
+		response["salon_name"] = card.Salon.Name
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGiftCards Get a salon's gift cards
+func GetGiftCards(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.GiftCard{}).Where("salon_id = ?", salonID)
+	if c.Query("outstanding") == "true" {
+		query = query.Where("balance > 0 AND (expires_at IS NULL OR expires_at > ?)", time.Now())
+	}
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at", Desc: true}}, "id", req, func(card models.GiftCard) uint { return card.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gift cards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetGiftCard Get a gift card with its transactions
+func GetGiftCard(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var card models.GiftCard
+	if err := database.DB.Where("id = ? AND salon_id = ?", c.Param("card_id"), salonID).First(&card).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gift card not found"})
+		return
+	}
+
+	var transactions []models.GiftCardTransaction
+	if err := database.DB.Where("gift_card_id = ?", card.ID).Order("id").Find(&transactions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch gift card transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"gift_card": card, "transactions": transactions})
+}
+
+// IssueGiftCard Issue a gift card with a new code
+func IssueGiftCard(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req IssueGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(giftCardValidity)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	code, err := giftcard.NewCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate gift card code"})
+		return
+	}
+
+	card := models.GiftCard{
+		SalonID:        salonID,
+		Code:           code,
+		InitialAmount:  req.Amount,
+		Balance:        req.Amount,
+		ExpiresAt:      &expiresAt,
+		PurchaserName:  req.PurchaserName,
+		RecipientName:  req.RecipientName,
+		RecipientEmail: req.RecipientEmail,
+		Note:           req.Note,
+		IssuedByUserID: c.GetUint("userID"),
+	}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&card).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.GiftCardTransaction{
+			GiftCardID: card.ID,
+			Type:       giftcard.TxIssue,
+			Amount:     card.InitialAmount,
+			Balance:    card.Balance,
+		}).Error
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue gift card"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
+
+// GetGiftCardLiability Report a salon's outstanding gift card balances
+func GetGiftCardLiability(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var report struct {
+		IssuedCount        int64 `json:"issued_count"`
+		IssuedAmount       int64 `json:"issued_amount"`
+		OutstandingCount   int64 `json:"outstanding_count"`
+		OutstandingBalance int64 `json:"outstanding_balance"` // Unexpired balances the salon still owes
+		ExpiredBalance     int64 `json:"expired_balance"`     // Balances left on expired cards
+	}
+	now := time.Now()
+	if err := database.DB.Model(&models.GiftCard{}).
+		Select(`COUNT(*) AS issued_count,
+			COALESCE(SUM(initial_amount), 0) AS issued_amount,
+			COUNT(*) FILTER (WHERE balance > 0 AND (expires_at IS NULL OR expires_at > ?)) AS outstanding_count,
+			COALESCE(SUM(balance) FILTER (WHERE expires_at IS NULL OR expires_at > ?), 0) AS outstanding_balance,
+			COALESCE(SUM(balance) FILTER (WHERE expires_at <= ?), 0) AS expired_balance`, now, now, now).
+		Where("salon_id = ?", salonID).
+		Scan(&report).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build gift card report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"salon_id": salonID, "as_of": now, "report": report})
+}
+
+// applyGiftCard Pay as much of the reservation as the gift card in GiftCardCode covers
+func applyGiftCard(reservation *models.Reservation) error {
+	code := reservation.GiftCardCode
+	reservation.GiftCardCode = ""
+	reservation.GiftCardID = nil
+	reservation.GiftCardAmount = 0
+	if code == "" {
+		return nil
+	}
+
+	var card models.GiftCard
+	if err := database.DB.Where("code = ? AND salon_id = ?", giftcard.NormalizeCode(code), reservation.SalonID).First(&card).Error; err != nil {
+		return errInvalidGiftCard
+	}
+	if card.Expired(time.Now()) {
+		return errGiftCardExpired
+	}
+	if card.Balance == 0 {
+		return errGiftCardEmpty
+	}
+
+	reservation.GiftCardID = &card.ID
+	reservation.GiftCardAmount = min(card.Balance, reservation.TotalPrice)
+	return nil
+}
+
+// redeemGiftCard Spend the gift card amount of a new reservation
+func redeemGiftCard(tx *gorm.DB, reservation models.Reservation) error {
+	if reservation.GiftCardID == nil || reservation.GiftCardAmount == 0 {
+		return nil
+	}
+
+	// The balance may have been spent or expired since the price was computed
+	result := tx.Model(&models.GiftCard{}).
+		Where("id = ? AND balance >= ? AND (expires_at IS NULL OR expires_at > ?)", *reservation.GiftCardID, reservation.GiftCardAmount, time.Now()).
+		UpdateColumn("balance", gorm.Expr("balance - ?", reservation.GiftCardAmount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errGiftCardUnavailable
+	}
+
+	return recordGiftCardTransaction(tx, *reservation.GiftCardID, reservation.ID, giftcard.TxRedeem, -reservation.GiftCardAmount)
+}
+
+// refundGiftCard Give back the gift card amount of a cancelled reservation per the salon's cancellation policy
+func refundGiftCard(tx *gorm.DB, reservation models.Reservation, cancelledAt time.Time) error {
+	if reservation.GiftCardID == nil || reservation.GiftCardAmount == 0 {
+		return nil
+	}
+
+	// Only once per reservation
+	var refunded int64
+	if err := tx.Model(&models.GiftCardTransaction{}).
+		Where("reservation_id = ? AND type = ?", reservation.ID, giftcard.TxRefund).
+		Count(&refunded).Error; err != nil {
+		return err
+	}
+	if refunded > 0 {
+		return nil
+	}
+
+	var salon models.Salon
+	if err := tx.Unscoped().First(&salon, reservation.SalonID).Error; err != nil {
+		return err
+	}
+	amount := salonPaymentPolicy(salon).RefundAmount(reservation.GiftCardAmount, reservation.StartTime, cancelledAt)
+	if amount == 0 {
+		return nil
+	}
+
+	if err := tx.Model(&models.GiftCard{}).Where("id = ?", *reservation.GiftCardID).
+		UpdateColumn("balance", gorm.Expr("balance + ?", amount)).Error; err != nil {
+		return err
+	}
+
+	return recordGiftCardTransaction(tx, *reservation.GiftCardID, reservation.ID, giftcard.TxRefund, amount)
+}
+
+// recordGiftCardTransaction Log a balance change with the resulting balance
+func recordGiftCardTransaction(tx *gorm.DB, cardID, reservationID uint, txType string, amount int) error {
+	var card models.GiftCard
+	if err := tx.Select("id", "balance").First(&card, cardID).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&models.GiftCardTransaction{
+		GiftCardID:    cardID,
+		ReservationID: &reservationID,
+		Type:          txType,
+		Amount:        amount,
+		Balance:       card.Balance,
+	}).Error
+}
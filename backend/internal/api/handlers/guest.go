@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/notification"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// Token purposes for scoped (non-login) tokens
+const (
+	tokenPurposeManageReservation = "manage_reservation"
+	tokenPurposeVerifyEmail       = "verify_email"
+)
+
+// manageTokenValidity How long a manage link stays valid after the appointment ends
+const manageTokenValidity = 30 * 24 * time.Hour
+
+type GuestReservationRequest struct {
+	SalonID       uint      `json:"salon_id" binding:"required"`
+	StaffID       uint      `json:"staff_id" binding:"required"`
+	ServiceID     uint      `json:"service_id" binding:"required"`
+	StartTime     time.Time `json:"start_time" binding:"required"`
+	Notes         string    `json:"notes"`
+	Name          string    `json:"name" binding:"required"`
+	Email         string    `json:"email" binding:"required,email"`
+	Phone         string    `json:"phone" binding:"required"`
+	CouponCode    string    `json:"coupon_code"`
+	GiftCardCode  string    `json:"gift_card_code"`
+	PaymentMethod string    `json:"payment_method"` // Gateway token, required when the salon takes a deposit or prepayment
+	HoldToken     string    `json:"hold_token"`     // Slot hold taken while filling in the form
+}
+
+type GuestReservationResponse struct {
+	Reservation models.Reservation `json:"reservation"`
+	ManageToken string             `json:"manage_token"`
+}
+
+// CreateGuestReservation Create reservation without an account
+func CreateGuestReservation(c *gin.Context) {
+	var req GuestReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation := models.Reservation{
+		SalonID:         req.SalonID,
+		StaffID:         req.StaffID,
+		ServiceID:       req.ServiceID,
+		ReservationDate: dayRange(req.StartTime.In(salonLocation)).Start,
+		StartTime:       req.StartTime,
+		Notes:           req.Notes,
+		GuestName:       req.Name,
+		GuestEmail:      req.Email,
+		GuestPhone:      req.Phone,
+		CouponCode:      req.CouponCode,
+		GiftCardCode:    req.GiftCardCode,
+		PaymentMethod:   req.PaymentMethod,
+		HoldToken:       req.HoldToken,
+	}
+
+	// Validate reservation
+	if err := validateReservation(&reservation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Price is computed server-side from the service and the salon's promotions
+	redemptions, err := priceReservation(&reservation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := applyGiftCard(&reservation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Deposit or prepayment required by the salon
+	charge, ok := chargeReservation(c, &reservation)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockStaffSchedule(tx, &reservation); err != nil {
+			return err
+		}
+		return insertReservation(tx, &reservation, redemptions, charge)
+	}); err != nil {
+		releaseCharge(charge)
+		var rejected *bookingError
+		if errors.As(err, &rejected) || errors.Is(err, errPromotionUnavailable) || errors.Is(err, errGiftCardUnavailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reservation"})
+		return
+	}
+
+	token, err := generateManageToken(reservation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	database.DB.Preload("Salon").Preload("Staff").Preload("Service").Preload("Payments").First(&reservation, reservation.ID)
+
+	go notifyReservation(notification.EventReservationCreated, reservation)
+
+	c.JSON(http.StatusCreated, GuestReservationResponse{
+		Reservation: reservation,
+		ManageToken: token,
+	})
+}
+
+// GetGuestReservation Get reservation details from a manage link token
+func GetGuestReservation(c *gin.Context) {
+	reservationID, err := parseManageToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var reservation models.Reservation
+	if err := database.DB.Preload("Salon").Preload("Staff").Preload("Service").Preload("Payments").First(&reservation, reservationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+// CancelGuestReservation Cancel reservation from a manage link token
+func CancelGuestReservation(c *gin.Context) {
+	reservationID, err := parseManageToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var reservation models.Reservation
+	if err := database.DB.First(&reservation, reservationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	if reservation.Status != "confirmed" || reservation.StartTime.Before(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reservation can no longer be cancelled"})
+		return
+	}
+
+	reservation.Status = "cancelled"
+	if err := cancelReservation(&reservation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel reservation"})
+		return
+	}
+
+	// Refund per the salon's cancellation policy once the cancellation is saved (failures are retried later)
+	refunded, refundErr := settleRefunds(c.Request.Context(), reservation.ID)
+	if refundErr != nil {
+		log.Printf("Failed to refund reservation %d: %v", reservation.ID, refundErr)
+	}
+
+	go notifyReservation(notification.EventReservationCancelled, reservation)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reservation cancelled successfully", "refunded_amount": refunded, "refund_pending": refundErr != nil})
+}
+
+// ConfirmGuestReservation Confirm attendance from a manage link token (sent with reminders)
+func ConfirmGuestReservation(c *gin.Context) {
+	reservationID, err := parseManageToken(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var reservation models.Reservation
+	if err := database.DB.First(&reservation, reservationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	if reservation.Status != "confirmed" || reservation.StartTime.Before(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reservation can no longer be confirmed"})
+		return
+	}
+
+	// Confirming again keeps the first confirmation time
+	if reservation.CustomerConfirmedAt == nil {
+		now := time.Now()
+		if err := database.DB.Model(&reservation).Update("customer_confirmed_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm reservation"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+// generateManageToken Generate a token granting access to a single reservation
+func generateManageToken(reservation models.Reservation) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose":        tokenPurposeManageReservation,
+		"reservation_id": reservation.ID,
+		"exp":            reservation.EndTime.Add(manageTokenValidity).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
+// parseManageToken Verify a manage link token and return its reservation ID
+func parseManageToken(tokenString string) (uint, error) {
+	claims, err := parseScopedToken(tokenString, tokenPurposeManageReservation)
+	if err != nil {
+		return 0, err
+	}
+
+	reservationID, ok := claims["reservation_id"].(float64)
+	if !ok {
+		return 0, errors.New("invalid reservation ID in token")
+	}
+
+	return uint(reservationID), nil
+}
+
+// parseScopedToken Verify a token and check that it was issued for the given purpose
+func parseScopedToken(tokenString, purpose string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/cache"
+	"reservation-platform-sample/internal/services/realtime"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// liveChanges Committed changes received from the database, fanned out to the open streams
+var liveChanges = realtime.NewBroker()
+
+// liveRefreshInterval Streams recompute slots (time passes, holds expire) and send a keep-alive this often
+const liveRefreshInterval = 30 * time.Second
+
+// RunLiveUpdates Receive committed reservation and hold changes until the context ends,
+// invalidating the cached availability and feeding the open streams
+func RunLiveUpdates(ctx context.Context, databaseURL string) {
+	realtime.Listen(ctx, databaseURL, func(change realtime.Change) {
+		// Dropped first so the streams recompute from the database
+		invalidateSlots(change.SalonID)
+		liveChanges.Publish(change)
+	})
+}
+
+// StreamAvailableSlots Stream a day's available slots as Server-Sent Events ("slots", same data as
+// GetAvailableSlots), sent on connect and again whenever reservations or holds change them
+func StreamAvailableSlots(c *gin.Context) {
+	salonID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid salon ID"})
+		return
+	}
+	staffID := c.Query("staff_id")
+	serviceID := c.Query("service_id")
+
+	parsedDate, err := time.ParseInLocation("2006-01-02", c.Query("date"), salonLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+	date := parsedDate.Format("2006-01-02")
+
+	// Subscribe before the first computation so no change falls in between
+	subscription := liveChanges.Subscribe(c.Request.Context(), uint(salonID))
+	staffFilter, _ := strconv.ParseUint(staffID, 10, 64)
+
+	slots, err := availableSlotsJSON(uint(salonID), staffID, serviceID, parsedDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticker := time.NewTicker(liveRefreshInterval)
+	defer ticker.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("slots", slots)
+	c.Writer.Flush()
+
+	sent := slots
+	c.Stream(func(w io.Writer) bool {
+		refresh := false
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case change := <-subscription.Changes():
+			if !change.Affects(date, uint(staffFilter)) {
+				return true
+			}
+		case <-ticker.C:
+			refresh = true
+		}
+
+		slots, err := availableSlotsJSON(uint(salonID), staffID, serviceID, parsedDate)
+		if err != nil {
+			log.Printf("Failed to compute slots of salon %d: %v", salonID, err)
+			return false
+		}
+		if slots != sent {
+			c.SSEvent("slots", slots)
+			sent = slots
+		} else if refresh {
+			io.WriteString(w, ": keep-alive\n\n")
+		}
+		return true
+	})
+}
+
+// StreamSalonActivity Stream a salon's reservation and hold changes to its admins as Server-Sent Events
+// named after the change type, with the reservation when there is one
+func StreamSalonActivity(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	subscription := liveChanges.Subscribe(c.Request.Context(), salonID)
+
+	ticker := time.NewTicker(liveRefreshInterval)
+	defer ticker.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case change := <-subscription.Changes():
+			event := gin.H{"change": change}
+			if change.ReservationID != 0 {
+				var reservation models.Reservation
+				if err := database.DB.Preload("User").Preload("Staff").Preload("Service").First(&reservation, change.ReservationID).Error; err == nil {
+					event["reservation"] = reservation
+				}
+			}
+			c.SSEvent(change.Type, event)
+		case <-ticker.C:
+			io.WriteString(w, ": keep-alive\n\n")
+		}
+		return true
+	})
+}
+
+// publishChange Notify live subscribers of a change when the transaction commits
+func publishChange(tx *gorm.DB, change realtime.Change) error {
+	change.At = time.Now()
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	return tx.Exec("SELECT pg_notify(?, ?)", realtime.Channel, string(payload)).Error
+}
+
+// reservationChange Live change for a reservation event
+func reservationChange(eventType string, reservation models.Reservation) realtime.Change {
+	return realtime.Change{
+		Type:          eventType,
+		SalonID:       reservation.SalonID,
+		StaffID:       reservation.StaffID,
+		Date:          reservation.StartTime.In(salonLocation).Format("2006-01-02"),
+		ReservationID: reservation.ID,
+	}
+}
+
+// availableSlotsJSON Encoded available slots, cached until the salon's availability changes.
+// The streams compare it between sends to skip unchanged ones.
+func availableSlotsJSON(salonID uint, staffID, serviceID string, date time.Time) (string, error) {
+	key := staffID + "|" + serviceID + "|" + date.Format("2006-01-02")
+	data, err := cache.Remember(context.Background(), responseCache, cacheKindSlots, slotsNamespace(salonID), key, slotsCacheTTL, func() (json.RawMessage, error) {
+		slots, err := availableSlots(strconv.FormatUint(uint64(salonID), 10), staffID, serviceID, date)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(slots)
+	})
+	return string(data), err
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/loyalty"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// loyaltyProgram Points earned, their value and how long they last
+var loyaltyProgram = loyalty.Program{EarnPercent: 1, YenPerPoint: 1, ExpiryDays: 365} // Should be obtained from environment variables
+
+// GetPoints Get the user's points balance and the next points to expire
+func GetPoints(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var balance int
+	var next *models.PointEntry
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockPoints(tx, userID, time.Now()); err != nil {
+			return err
+		}
+
+		var err error
+		if balance, err = pointBalance(tx, userID); err != nil {
+			return err
+		}
+
+		var credit models.PointEntry
+		err = tx.Where("user_id = ? AND remaining > 0", userID).Order("expires_at, id").First(&credit).Error
+		if err == nil {
+			next = &credit
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points"})
+		return
+	}
+
+	response := gin.H{
+		"balance":       balance,
+		"value":         loyaltyProgram.Value(balance),
+		"yen_per_point": loyaltyProgram.YenPerPoint,
+		"earn_percent":  loyaltyProgram.EarnPercent,
+	}
+	if next != nil {
+		response["next_expiry"] = gin.H{"points": next.Remaining, "expires_at": next.ExpiresAt}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPointHistory Get the user's points ledger (latest first)
+func GetPointHistory(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Record expiries that are due so the history is up to date
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return lockPoints(tx, userID, time.Now())
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points history"})
+		return
+	}
+
+	query := database.DB.Model(&models.PointEntry{}).Where("user_id = ?", userID)
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at", Desc: true}}, "id", req, func(entry models.PointEntry) uint { return entry.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// availablePoints Points a user can spend now (before any pending expiry is recorded)
+func availablePoints(userID uint, now time.Time) (int, error) {
+	var points int
+	err := database.DB.Model(&models.PointEntry{}).
+		Where("user_id = ? AND remaining > 0 AND expires_at > ?", userID, now).
+		Select("COALESCE(SUM(remaining), 0)").
+		Scan(&points).Error
+	return points, err
+}
+
+// lockPoints Serialize ledger changes for a user and record the expiries that are due.
+// Must run inside a transaction.
+func lockPoints(tx *gorm.DB, userID uint, now time.Time) error {
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, userID).Error; err != nil {
+		return err
+	}
+	return expirePoints(tx, userID, now)
+}
+
+// expirePoints Record the expiry of the user's credits that are due (the ledger must be locked)
+func expirePoints(tx *gorm.DB, userID uint, now time.Time) error {
+	var expired []models.PointEntry
+	if err := tx.Where("user_id = ? AND remaining > 0 AND expires_at <= ?", userID, now).
+		Order("expires_at, id").Find(&expired).Error; err != nil {
+		return err
+	}
+
+	for _, credit := range expired {
+		creditID := credit.ID
+		if err := addPointEntry(tx, &models.PointEntry{
+			UserID:      userID,
+			Type:        loyalty.EntryExpire,
+			Points:      -credit.Remaining,
+			CreditID:    &creditID,
+			Description: "Points expired",
+		}); err != nil {
+			return err
+		}
+		if err := tx.Model(&credit).UpdateColumn("remaining", 0).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pointBalance Balance after the user's latest ledger entry
+func pointBalance(tx *gorm.DB, userID uint) (int, error) {
+	var last models.PointEntry
+	err := tx.Where("user_id = ?", userID).Order("id DESC").First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return last.Balance, err
+}
+
+// addPointEntry Append an entry to the user's ledger with the resulting balance
+func addPointEntry(tx *gorm.DB, entry *models.PointEntry) error {
+	balance, err := pointBalance(tx, entry.UserID)
+	if err != nil {
+		return err
+	}
+	entry.Balance = balance + entry.Points
+	return tx.Create(entry).Error
+}
+
+// earnPoints Credit the points of a completed reservation
+func earnPoints(tx *gorm.DB, reservation models.Reservation) error {
+	points := loyaltyProgram.Earned(reservation.TotalPrice)
+	if reservation.UserID == nil || points == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if err := lockPoints(tx, *reservation.UserID, now); err != nil {
+		return err
+	}
+
+	expiresAt := loyaltyProgram.ExpiresAt(now)
+	return addPointEntry(tx, &models.PointEntry{
+		UserID:        *reservation.UserID,
+		Type:          loyalty.EntryEarn,
+		Points:        points,
+		Remaining:     points,
+		ExpiresAt:     &expiresAt,
+		ReservationID: &reservation.ID,
+		Description:   "Earned for a completed reservation",
+	})
+}
+
+// redeemPoints Debit the points used by a new reservation, spending the credits that expire first
+func redeemPoints(tx *gorm.DB, reservation models.Reservation) error {
+	if reservation.PointsUsed == 0 || reservation.UserID == nil {
+		return nil
+	}
+	userID := *reservation.UserID
+
+	if err := lockPoints(tx, userID, time.Now()); err != nil {
+		return err
+	}
+
+	var credits []models.PointEntry
+	if err := tx.Where("user_id = ? AND remaining > 0", userID).Order("expires_at, id").Find(&credits).Error; err != nil {
+		return err
+	}
+
+	left := reservation.PointsUsed
+	for _, credit := range credits {
+		if left == 0 {
+			break
+		}
+		spent := min(credit.Remaining, left)
+		if err := tx.Model(&credit).UpdateColumn("remaining", credit.Remaining-spent).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.PointRedemption{ReservationID: reservation.ID, CreditID: credit.ID, Points: spent}).Error; err != nil {
+			return err
+		}
+		left -= spent
+	}
+	if left > 0 {
+		return loyalty.ErrInsufficientPoints
+	}
+
+	return addPointEntry(tx, &models.PointEntry{
+		UserID:        userID,
+		Type:          loyalty.EntryRedeem,
+		Points:        -reservation.PointsUsed,
+		ReservationID: &reservation.ID,
+		Description:   "Redeemed for a reservation",
+	})
+}
+
+// refundPoints Give back the points redeemed by a cancelled reservation to the credits they were spent from.
+// The credits keep their original expiry, so points that expired in the meantime expire again right away.
+func refundPoints(tx *gorm.DB, reservation models.Reservation) error {
+	if reservation.PointsUsed == 0 || reservation.UserID == nil {
+		return nil
+	}
+	userID := *reservation.UserID
+
+	now := time.Now()
+	if err := lockPoints(tx, userID, now); err != nil {
+		return err
+	}
+
+	// Only once per reservation
+	var refunded int64
+	if err := tx.Model(&models.PointEntry{}).
+		Where("reservation_id = ? AND type = ?", reservation.ID, loyalty.EntryRefund).
+		Count(&refunded).Error; err != nil {
+		return err
+	}
+	if refunded > 0 {
+		return nil
+	}
+
+	var redemptions []models.PointRedemption
+	if err := tx.Where("reservation_id = ?", reservation.ID).Order("id").Find(&redemptions).Error; err != nil {
+		return err
+	}
+
+	restored := 0
+	for _, redemption := range redemptions {
+		if err := tx.Model(&models.PointEntry{}).Where("id = ?", redemption.CreditID).
+			UpdateColumn("remaining", gorm.Expr("remaining + ?", redemption.Points)).Error; err != nil {
+			return err
+		}
+		restored += redemption.Points
+	}
+
+	// Reservations booked before spent credits were recorded get a new credit for the rest
+	refund := models.PointEntry{
+		UserID:        userID,
+		Type:          loyalty.EntryRefund,
+		Points:        reservation.PointsUsed,
+		ReservationID: &reservation.ID,
+		Description:   "Refunded for a cancelled reservation",
+	}
+	if rest := reservation.PointsUsed - restored; rest > 0 {
+		expiresAt := loyaltyProgram.ExpiresAt(now)
+		refund.Remaining = rest
+		refund.ExpiresAt = &expiresAt
+	}
+	if err := addPointEntry(tx, &refund); err != nil {
+		return err
+	}
+
+	return expirePoints(tx, userID, now)
+}
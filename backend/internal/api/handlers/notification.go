@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/ical"
+	"reservation-platform-sample/internal/services/notification"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// notifier Sends customer and salon notifications (local log sinks until providers are configured)
+var notifier = notification.NewService(
+	notification.NewLogSender(notification.ChannelEmail),
+	notification.NewLogSender(notification.ChannelSMS),
+	notification.NewLogSender(notification.ChannelPush),
+)
+
+// emailVerificationBaseURL Frontend page that verifies an email address with the token
+var emailVerificationBaseURL = "http://localhost:3000/verify-email" // Should be obtained from environment variables
+
+// salonNotificationEvents Salon-side event for each customer event
+var salonNotificationEvents = map[string]string{
+	notification.EventReservationCreated:   notification.EventSalonReservationCreated,
+	notification.EventReservationUpdated:   notification.EventSalonReservationUpdated,
+	notification.EventReservationCancelled: notification.EventSalonReservationCancelled,
+}
+
+type NotificationPreferenceRequest struct {
+	Email     bool   `json:"email"`
+	SMS       bool   `json:"sms"`
+	Push      bool   `json:"push"`
+	Locale    string `json:"locale" binding:"required,oneof=ja en"`
+	PushToken string `json:"push_token"`
+}
+
+// GetNotificationPreferences Get the user's notification channels and language
+func GetNotificationPreferences(c *gin.Context) {
+	preference, err := loadNotificationPreference(c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+// UpdateNotificationPreferences Set the user's notification channels and language
+func UpdateNotificationPreferences(c *gin.Context) {
+	var req NotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preference, err := loadNotificationPreference(c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification preferences"})
+		return
+	}
+
+	preference.Email = req.Email
+	preference.SMS = req.SMS
+	preference.Push = req.Push
+	preference.Locale = req.Locale
+	preference.PushToken = req.PushToken
+
+	// Rule violations are reported by the model hook
+	if err := database.DB.Save(&preference).Error; err != nil {
+		if errors.Is(err, models.ErrPushTokenRequired) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": models.ErrPushTokenRequired.Error()})
+			return
+		}
+		log.Printf("Failed to save notification preferences of user %d: %v", preference.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+// loadNotificationPreference Stored preferences of a user, or the defaults
+func loadNotificationPreference(userID uint) (models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+	err := database.DB.Where("user_id = ?", userID).First(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Users who have not chosen any get email in Japanese
+		return models.NotificationPreference{UserID: userID, Email: true, Locale: notification.LocaleJapanese}, nil
+	}
+	return preference, err
+}
+
+// notifyReservation Tell the customer and the salon about a reservation change.
+// Runs after the change is saved; failures are logged and never affect the request.
+func notifyReservation(event string, reservation models.Reservation) {
+	customer, data, salon, err := reservationNotification(reservation)
+	if err != nil {
+		log.Printf("Failed to load reservation %d for notification: %v", reservation.ID, err)
+		return
+	}
+
+	// Customers get the calendar file so the event is added, moved or cancelled in their own calendar
+	var attachments []notification.Attachment
+	if _, ok := salonNotificationEvents[event]; ok {
+		withDetails := reservation
+		withDetails.Salon = &salon
+		withDetails.Staff = &models.Staff{Name: data.StaffName}
+		withDetails.Service = &models.Service{Name: data.ServiceName}
+		attachments = append(attachments, notification.Attachment{
+			Filename:    fmt.Sprintf("reservation-%d.ics", reservation.ID),
+			ContentType: ical.ContentType,
+			Content:     reservationCalendar(withDetails),
+		})
+	}
+
+	ctx := context.Background()
+	if _, err := notifier.Notify(ctx, customer, event, data, attachments...); err != nil {
+		log.Printf("Failed to notify customer of reservation %d: %v", reservation.ID, err)
+	}
+
+	if salonEvent, ok := salonNotificationEvents[event]; ok && salon.Email != "" {
+		recipient := notification.Recipient{Email: salon.Email, Locale: notification.LocaleJapanese, Channels: []string{notification.ChannelEmail}}
+		if _, err := notifier.Notify(ctx, recipient, salonEvent, data); err != nil {
+			log.Printf("Failed to notify salon of reservation %d: %v", reservation.ID, err)
+		}
+	}
+}
+
+// notifyEmailVerification Email the link verifying the user's address (failures are logged)
+func notifyEmailVerification(user models.User, token string) {
+	preference, err := loadNotificationPreference(user.ID)
+	if err != nil {
+		log.Printf("Failed to load notification preferences of user %d: %v", user.ID, err)
+	}
+
+	// Sent to the address being verified whatever channels the user prefers
+	recipient := notification.Recipient{Email: user.Email, Locale: preference.Locale, Channels: []string{notification.ChannelEmail}}
+	data := notification.EmailVerification{
+		Name:      user.Name,
+		VerifyURL: emailVerificationBaseURL + "?" + url.Values{"token": {token}}.Encode(),
+	}
+	if _, err := notifier.Notify(context.Background(), recipient, notification.EventEmailVerification, data); err != nil {
+		log.Printf("Failed to send email verification to user %d: %v", user.ID, err)
+	}
+}
+
+// reservationNotification Customer to notify about a reservation, the template data and the salon
+func reservationNotification(reservation models.Reservation) (notification.Recipient, notification.Reservation, models.Salon, error) {
+	var salon models.Salon
+	var staff models.Staff
+	var service models.Service
+	if err := database.DB.Unscoped().First(&salon, reservation.SalonID).Error; err != nil {
+		return notification.Recipient{}, notification.Reservation{}, salon, err
+	}
+	database.DB.Unscoped().First(&staff, reservation.StaffID)
+	database.DB.Unscoped().First(&service, reservation.ServiceID)
+
+	customer := notification.Recipient{
+		Email:    reservation.GuestEmail,
+		Phone:    reservation.GuestPhone,
+		Locale:   notification.LocaleJapanese,
+		Channels: []string{notification.ChannelEmail},
+	}
+	customerName := reservation.GuestName
+	if reservation.UserID != nil {
+		var user models.User
+		if err := database.DB.First(&user, *reservation.UserID).Error; err != nil {
+			return notification.Recipient{}, notification.Reservation{}, salon, err
+		}
+		preference, err := loadNotificationPreference(user.ID)
+		if err != nil {
+			return notification.Recipient{}, notification.Reservation{}, salon, err
+		}
+		customer = notification.Recipient{
+			Email:     user.Email,
+			Phone:     user.Phone,
+			PushToken: preference.PushToken,
+			Locale:    preference.Locale,
+			Channels:  notification.EnabledChannels(preference.Email, preference.SMS, preference.Push),
+		}
+		customerName = user.Name
+	} else if reservation.GuestEmail == "" {
+		// Guests booked by phone at the salon only have a phone number
+		customer.Channels = []string{notification.ChannelSMS}
+	}
+
+	data := notification.Reservation{
+		ID:           reservation.ID,
+		CustomerName: customerName,
+		SalonName:    salon.Name,
+		ServiceName:  service.Name,
+		StaffName:    staff.Name,
+		StartTime:    reservation.StartTime.In(salonLocation),
+		TotalPrice:   reservation.TotalPrice,
+	}
+	return customer, data, salon, nil
+}
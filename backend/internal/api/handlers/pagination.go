@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// List page size limits
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// errInvalidCursor Cursor that cannot be decoded or does not belong to the requested sort order
+var errInvalidCursor = errors.New("invalid cursor")
+
+// sortKey Expression a list is ordered by
+type sortKey struct {
+	Expr string
+	Args []interface{}
+	Desc bool
+}
+
+// pageRequest Page size and decoded cursor of a list request
+type pageRequest struct {
+	Limit  int
+	Cursor *pageCursor
+}
+
+// pageCursor Position after the last row of the previous page: its sort key values
+// (as PostgreSQL text representations, nil for NULL) and its ID as the tie-breaker
+type pageCursor struct {
+	Values []*string `json:"v"`
+	ID     uint      `json:"id"`
+}
+
+// Page Shared response envelope of list endpoints
+type Page[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+	HasMore    bool    `json:"has_more"`
+	Total      *int64  `json:"total,omitempty"`
+}
+
+// parsePageRequest Parse the limit and cursor query parameters
+func parsePageRequest(c *gin.Context) (pageRequest, error) {
+	req := pageRequest{Limit: defaultPageLimit}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return req, fmt.Errorf("invalid limit (1-%d)", maxPageLimit)
+		}
+		req.Limit = limit
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			return req, errInvalidCursor
+		}
+		var cursor pageCursor
+		if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == 0 {
+			return req, errInvalidCursor
+		}
+		req.Cursor = &cursor
+	}
+
+	return req, nil
+}
+
+// encode Encode the cursor as an opaque URL-safe string
+func (p pageCursor) encode() string {
+	raw, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// fetchPage Fetch one page of a keyset-paginated list.
+// base is the filtered query (without ordering) and idColumn its primary key column, which
+// breaks ties so the order is stable. The cursor holds the sort values of the last row, read
+// back from the database so that computed sort keys (distance, rank, ...) work the same way.
+func fetchPage[T any](base *gorm.DB, keys []sortKey, idColumn string, req pageRequest, id func(T) uint) (Page[T], error) {
+	page := Page[T]{Data: []T{}}
+
+	query := base.Session(&gorm.Session{})
+	if req.Cursor != nil {
+		if len(req.Cursor.Values) != len(keys) {
+			return page, errInvalidCursor
+		}
+		condition, args := keysetCondition(keys, idColumn, *req.Cursor)
+		query = query.Where(condition, args...)
+	}
+	query = query.Clauses(orderBy(keys, idColumn))
+
+	// One extra row tells whether another page follows
+	var rows []T
+	if err := query.Limit(req.Limit + 1).Find(&rows).Error; err != nil {
+		return page, err
+	}
+	if len(rows) > req.Limit {
+		rows = rows[:req.Limit]
+		page.HasMore = true
+	}
+	page.Data = append(page.Data, rows...)
+
+	if page.HasMore {
+		lastID := id(rows[len(rows)-1])
+		values, err := sortValues(base, keys, idColumn, lastID)
+		if err != nil {
+			return page, err
+		}
+		next := pageCursor{Values: values, ID: lastID}.encode()
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+// keysetCondition Build the "comes after the cursor" condition:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... OR (k1 = v1 AND ... AND id > lastID), with < for descending keys.
+// NULLs sort last in both directions, so a NULL cursor value is only followed by other NULLs
+// and a non-NULL one also by NULLs.
+func keysetCondition(keys []sortKey, idColumn string, cursor pageCursor) (string, []interface{}) {
+	var alternatives []string
+	var args []interface{}
+
+	for i := 0; i <= len(keys); i++ {
+		// Nothing sorts after NULL on this key, only the following keys can tell rows apart
+		if i < len(keys) && cursor.Values[i] == nil {
+			continue
+		}
+
+		var terms []string
+		for j := 0; j < i; j++ {
+			if cursor.Values[j] == nil {
+				terms = append(terms, keys[j].Expr+" IS NULL")
+				args = append(args, keys[j].Args...)
+				continue
+			}
+			terms = append(terms, keys[j].Expr+" = ?")
+			args = append(append(args, keys[j].Args...), *cursor.Values[j])
+		}
+		if i == len(keys) {
+			terms = append(terms, idColumn+" > ?")
+			args = append(args, cursor.ID)
+		} else {
+			operator := ">"
+			if keys[i].Desc {
+				operator = "<"
+			}
+			terms = append(terms, "("+keys[i].Expr+" "+operator+" ? OR "+keys[i].Expr+" IS NULL)")
+			args = append(append(append(args, keys[i].Args...), *cursor.Values[i]), keys[i].Args...)
+		}
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// sortValues Read the sort key values of a row as PostgreSQL text representations (nil for NULL)
+func sortValues(base *gorm.DB, keys []sortKey, idColumn string, id uint) ([]*string, error) {
+	if len(keys) == 0 {
+		return []*string{}, nil
+	}
+
+	columns := make([]string, len(keys))
+	var args []interface{}
+	for i, key := range keys {
+		columns[i] = key.Expr
+		args = append(args, key.Args...)
+	}
+
+	raw := make([]interface{}, len(keys))
+	targets := make([]interface{}, len(keys))
+	for i := range raw {
+		targets[i] = &raw[i]
+	}
+
+	row := base.Session(&gorm.Session{}).
+		Select(strings.Join(columns, ", "), args...).
+		Where(idColumn+" = ?", id).
+		Row()
+	if err := row.Scan(targets...); err != nil {
+		return nil, err
+	}
+
+	values := make([]*string, len(raw))
+	for i, value := range raw {
+		var text string
+		switch v := value.(type) {
+		case nil:
+			continue
+		case time.Time:
+			text = v.Format(time.RFC3339Nano)
+		case float64:
+			text = strconv.FormatFloat(v, 'g', -1, 64)
+		case []byte:
+			text = string(v)
+		default:
+			text = fmt.Sprint(v)
+		}
+		values[i] = &text
+	}
+	return values, nil
+}
+
+// orderBy Build the ORDER BY clause of the sort keys (NULLs last) followed by the ID tie-breaker
+func orderBy(keys []sortKey, idColumn string) clause.OrderBy {
+	var columns []string
+	var args []interface{}
+	for _, key := range keys {
+		direction := " ASC NULLS LAST"
+		if key.Desc {
+			direction = " DESC NULLS LAST"
+		}
+		columns = append(columns, key.Expr+direction)
+		args = append(args, key.Args...)
+	}
+	columns = append(columns, idColumn)
+
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(columns, ", "), Vars: args}}
+}
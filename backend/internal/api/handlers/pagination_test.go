@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"reservation-platform-sample/internal/infrastructure/database"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+func text(s string) *string {
+	return &s
+}
+
+func TestKeysetCondition(t *testing.T) {
+	rating := sortKey{Expr: "rating", Desc: true}
+	distance := sortKey{Expr: "distance(?)", Args: []interface{}{"here"}}
+
+	tests := []struct {
+		name     string
+		keys     []sortKey
+		values   []*string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "value",
+			keys:     []sortKey{rating},
+			values:   []*string{text("4.5")},
+			wantSQL:  "(((rating < ? OR rating IS NULL)) OR (rating = ? AND id > ?))",
+			wantArgs: []interface{}{"4.5", "4.5", uint(7)},
+		},
+		{
+			name:     "NULL is only followed by NULLs",
+			keys:     []sortKey{rating},
+			values:   []*string{nil},
+			wantSQL:  "((rating IS NULL AND id > ?))",
+			wantArgs: []interface{}{uint(7)},
+		},
+		{
+			name:     "NULL first key with arguments on the second",
+			keys:     []sortKey{rating, distance},
+			values:   []*string{nil, text("1.2")},
+			wantSQL:  "((rating IS NULL AND (distance(?) > ? OR distance(?) IS NULL)) OR (rating IS NULL AND distance(?) = ? AND id > ?))",
+			wantArgs: []interface{}{"here", "1.2", "here", "here", "1.2", uint(7)},
+		},
+		{
+			name:     "NULL second key",
+			keys:     []sortKey{distance, rating},
+			values:   []*string{text("1.2"), nil},
+			wantSQL:  "(((distance(?) > ? OR distance(?) IS NULL)) OR (distance(?) = ? AND rating IS NULL AND id > ?))",
+			wantArgs: []interface{}{"here", "1.2", "here", "here", "1.2", uint(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := keysetCondition(tt.keys, "id", pageCursor{Values: tt.values, ID: 7})
+			if sql != tt.wantSQL {
+				t.Errorf("condition = %s\nwant %s", sql, tt.wantSQL)
+			}
+			if fmt.Sprint(args) != fmt.Sprint(tt.wantArgs) || strings.Count(sql, "?") != len(args) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestOrderByNullsLast(t *testing.T) {
+	order := orderBy([]sortKey{{Expr: "rating", Desc: true}, {Expr: "name"}}, "id")
+	want := "rating DESC NULLS LAST, name ASC NULLS LAST, id"
+	if got := order.Expression.(clause.Expr).SQL; got != want {
+		t.Errorf("ORDER BY %s, want %s", got, want)
+	}
+}
+
+func TestPageCursorNullValues(t *testing.T) {
+	cursor := pageCursor{Values: []*string{nil, text("2024-01-01T00:00:00Z")}, ID: 9}
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?cursor="+cursor.encode(), nil)
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := req.Cursor
+	if got.ID != 9 || len(got.Values) != 2 || got.Values[0] != nil || got.Values[1] == nil || *got.Values[1] != "2024-01-01T00:00:00Z" {
+		t.Errorf("decoded cursor = %+v, want a NULL and a time value", got)
+	}
+}
+
+func TestFetchPageNullSortKeys(t *testing.T) {
+	testDatabase(t)
+
+	type row struct {
+		ID    uint
+		Score *int
+	}
+	base := database.DB.Table("(VALUES (1, 3), (2, NULL), (3, 1), (4, NULL), (5, 3), (6, 2)) AS t(id, score)")
+
+	for _, desc := range []bool{false, true} {
+		keys := []sortKey{{Expr: "score", Desc: desc}}
+
+		// Pages of two must walk every row once, NULLs last
+		var ids []uint
+		req := pageRequest{Limit: 2}
+		for pages := 0; pages < 10; pages++ {
+			page, err := fetchPage(base, keys, "id", req, func(r row) uint { return r.ID })
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range page.Data {
+				ids = append(ids, r.ID)
+			}
+			if !page.HasMore {
+				break
+			}
+			raw, _ := base64.RawURLEncoding.DecodeString(*page.NextCursor)
+			var cursor pageCursor
+			if err := json.Unmarshal(raw, &cursor); err != nil {
+				t.Fatal(err)
+			}
+			req.Cursor = &cursor
+		}
+
+		want := "[3 6 1 5 2 4]"
+		if desc {
+			want = "[1 5 6 3 2 4]"
+		}
+		if fmt.Sprint(ids) != want {
+			t.Errorf("desc=%v: rows %v, want %s", desc, ids, want)
+		}
+	}
+}
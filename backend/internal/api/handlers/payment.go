@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/payment"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// paymentGateway Gateway charging deposits and prepayments (set by UsePaymentGateway)
+var paymentGateway payment.Gateway
+
+// refundableStatuses Payment statuses that can still give money back
+var refundableStatuses = []string{payment.StatusAuthorized, payment.StatusCaptured, payment.StatusPartiallyRefunded}
+
+// UsePaymentGateway Set the gateway used for charges and refunds
+func UsePaymentGateway(gateway payment.Gateway) {
+	paymentGateway = gateway
+}
+
+// salonPaymentPolicy Payment policy of a salon
+func salonPaymentPolicy(salon models.Salon) payment.Policy {
+	return payment.Policy{
+		Mode:                          salon.PaymentPolicy,
+		DepositPercent:                salon.DepositPercent,
+		FreeCancellationHours:         salon.FreeCancelHours,
+		LateCancellationRefundPercent: salon.CancelRefundPercent,
+	}
+}
+
+// chargeReservation Authorize and capture the deposit or prepayment required by the salon.
+// Returns a nil payment when the salon is paid on site. Writes the error response on failure.
+func chargeReservation(c *gin.Context, reservation *models.Reservation) (*models.Payment, bool) {
+	var salon models.Salon
+	if err := database.DB.First(&salon, reservation.SalonID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "salon not found"})
+		return nil, false
+	}
+
+	// The part paid with a gift card is not charged again
+	policy := salonPaymentPolicy(salon)
+	amount := policy.AmountDue(reservation.TotalPrice - reservation.GiftCardAmount)
+	if amount == 0 {
+		return nil, true
+	}
+	if reservation.PaymentMethod == "" {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "payment_method is required by this salon", "amount_due": amount})
+		return nil, false
+	}
+
+	ctx := c.Request.Context()
+	authorized, err := paymentGateway.Authorize(ctx, payment.AuthorizeRequest{
+		Amount:         amount,
+		PaymentMethod:  reservation.PaymentMethod,
+		Description:    "Reservation at " + salon.Name,
+		IdempotencyKey: c.GetHeader("Idempotency-Key"),
+	})
+	if errors.Is(err, payment.ErrDeclined) {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "Payment declined"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Payment failed"})
+		return nil, false
+	}
+
+	captured, err := paymentGateway.Capture(ctx, authorized.PaymentID, amount)
+	if err != nil {
+		releasePayment(authorized.PaymentID, amount)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Payment failed"})
+		return nil, false
+	}
+	reservation.PaymentMethod = ""
+
+	return &models.Payment{
+		Provider:          paymentGateway.Name(),
+		ProviderPaymentID: captured.PaymentID,
+		Kind:              policy.Mode,
+		Status:            captured.Status,
+		Amount:            captured.Amount,
+		CapturedAmount:    captured.CapturedAmount,
+	}, true
+}
+
+// releaseCharge Give back a booking charge when the reservation could not be saved
+func releaseCharge(charge *models.Payment) {
+	if charge != nil {
+		releasePayment(charge.ProviderPaymentID, charge.CapturedAmount)
+	}
+}
+
+// releasePayment Give back a charge that could not be recorded (failures are logged for manual follow-up)
+func releasePayment(paymentID string, amount int) {
+	if _, err := paymentGateway.Refund(context.Background(), paymentID, amount); err != nil {
+		log.Printf("Failed to release payment %s: %v", paymentID, err)
+	}
+}
+
+// scheduleRefunds Record what a cancelled reservation's payments owe back under the salon's cancellation policy.
+// Runs in the cancelling transaction; settleRefunds pays it out once the cancellation is committed.
+func scheduleRefunds(tx *gorm.DB, reservation models.Reservation, cancelledAt time.Time) error {
+	var payments []models.Payment
+	if err := tx.Where("reservation_id = ? AND status IN ?", reservation.ID, refundableStatuses).Find(&payments).Error; err != nil {
+		return err
+	}
+	if len(payments) == 0 {
+		return nil
+	}
+
+	var salon models.Salon
+	if err := tx.Unscoped().First(&salon, reservation.SalonID).Error; err != nil {
+		return err
+	}
+	policy := salonPaymentPolicy(salon)
+
+	for _, p := range payments {
+		// Authorizations that were never captured are released in full
+		due := p.Amount
+		if p.Status != payment.StatusAuthorized {
+			due = policy.RefundAmount(p.CapturedAmount, reservation.StartTime, cancelledAt)
+		}
+		if due <= p.RefundDue {
+			continue
+		}
+		if err := tx.Model(&p).Update("refund_due", due).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settleRefunds Pay out what a reservation's payments still owe. Returns the amount refunded by this call;
+// a failed refund stays owed and is retried by RunRefundSettler.
+func settleRefunds(ctx context.Context, reservationID uint) (int, error) {
+	var paymentIDs []uint
+	if err := database.DB.Model(&models.Payment{}).
+		Where("reservation_id = ? AND status IN ? AND refund_due > refunded_amount", reservationID, refundableStatuses).
+		Order("id").Pluck("id", &paymentIDs).Error; err != nil {
+		return 0, err
+	}
+
+	refunded := 0
+	for _, paymentID := range paymentIDs {
+		amount, err := settleRefund(ctx, paymentID)
+		refunded += amount
+		if err != nil {
+			return refunded, err
+		}
+	}
+	return refunded, nil
+}
+
+// settleRefund Refund what a payment still owes. The payment row stays locked during the gateway call
+// so that the request and the background settler never refund it twice.
+func settleRefund(ctx context.Context, paymentID uint) (int, error) {
+	refunded := 0
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var p models.Payment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status IN ?", paymentID, refundableStatuses).
+			First(&p).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		amount := p.RefundDue - p.RefundedAmount
+		if amount <= 0 {
+			return nil
+		}
+
+		result, err := paymentGateway.Refund(ctx, p.ProviderPaymentID, amount)
+		if err != nil {
+			return err
+		}
+
+		p.Status = result.Status
+		p.RefundedAmount = result.RefundedAmount
+		if err := tx.Save(&p).Error; err != nil {
+			return err
+		}
+		refunded = amount
+		return nil
+	})
+	return refunded, err
+}
+
+// RunRefundSettler Periodically retry the refunds of cancelled reservations that could not be paid out
+func RunRefundSettler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var paymentIDs []uint
+		if err := database.DB.Model(&models.Payment{}).
+			Where("status IN ? AND refund_due > refunded_amount", refundableStatuses).
+			Order("id").Pluck("id", &paymentIDs).Error; err != nil {
+			log.Printf("Failed to fetch pending refunds: %v", err)
+			continue
+		}
+		for _, paymentID := range paymentIDs {
+			if _, err := settleRefund(ctx, paymentID); err != nil {
+				log.Printf("Failed to refund payment %d: %v", paymentID, err)
+			}
+		}
+	}
+}
+
+// PaymentWebhook Receive payment changes from the gateway
+func PaymentWebhook(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read body"})
+		return
+	}
+
+	event, err := paymentGateway.ParseWebhook(body, c.GetHeader("X-Payment-Signature"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook"})
+		return
+	}
+
+	duplicate := false
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var p models.Payment
+		found := tx.Where("provider = ? AND provider_payment_id = ?", paymentGateway.Name(), event.PaymentID).First(&p).Error == nil
+
+		record := models.PaymentEvent{
+			Provider:   paymentGateway.Name(),
+			EventID:    event.ID,
+			Type:       event.Type,
+			Payload:    string(body),
+			ReceivedAt: time.Now(),
+		}
+		if found {
+			record.PaymentID = &p.ID
+		}
+
+		// Gateways redeliver events; only the first delivery is applied
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			duplicate = true
+			return nil
+		}
+		if !found {
+			return nil
+		}
+
+		switch event.Type {
+		case payment.EventCaptured:
+			p.CapturedAmount = event.Amount
+			p.Status = payment.StatusCaptured
+		case payment.EventRefunded:
+			p.RefundedAmount = event.Amount
+			p.Status = payment.StatusPartiallyRefunded
+			if p.RefundedAmount >= p.CapturedAmount {
+				p.Status = payment.StatusRefunded
+			}
+		case payment.EventFailed:
+			p.Status = payment.StatusFailed
+		default:
+			return nil
+		}
+		return tx.Save(&p).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true, "duplicate": duplicate})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPriceRules Get a salon's price rules
+func GetPriceRules(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.PriceRule{}).Where("salon_id = ?", salonID)
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at", Desc: true}}, "id", req, func(rule models.PriceRule) uint { return rule.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreatePriceRule Create a price rule
+func CreatePriceRule(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var rule models.PriceRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule.ID = 0
+	rule.SalonID = salonID
+
+	// Rule violations are reported by the model hook
+	if err := database.DB.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	invalidateSlots(salonID)
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdatePriceRule Update a price rule (existing reservations keep their price)
+func UpdatePriceRule(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var rule models.PriceRule
+	if err := database.DB.Where("id = ? AND salon_id = ?", c.Param("rule_id"), salonID).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Price rule not found"})
+		return
+	}
+
+	id := rule.ID
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rule.ID, rule.SalonID = id, salonID
+
+	if err := database.DB.Save(&rule).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	invalidateSlots(salonID)
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeletePriceRule Delete a price rule
+func DeletePriceRule(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND salon_id = ?", c.Param("rule_id"), salonID).Delete(&models.PriceRule{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete price rule"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Price rule not found"})
+		return
+	}
+	invalidateSlots(salonID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Price rule deleted successfully"})
+}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/loyalty"
+	"reservation-platform-sample/internal/services/pricing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errInvalidCoupon        = errors.New("invalid coupon code")
+	errCouponNotApplicable  = errors.New("coupon does not apply to this reservation")
+	errPromotionUnavailable = errors.New("promotion is no longer available")
+)
+
+// priceList Service price, price rules and promotions of a salon, loaded once to price one or many bookings
+type priceList struct {
+	service    models.Service
+	rules      []models.PriceRule
+	promotions []models.Promotion
+}
+
+// loadPriceList Load the active price rules and promotions for a service.
+// Coupons are only loaded when their code is given.
+func loadPriceList(service models.Service, couponCode string) (*priceList, error) {
+	list := &priceList{service: service}
+
+	if err := database.DB.Where("salon_id = ? AND is_active = ?", service.SalonID, true).
+		Order("id").Find(&list.rules).Error; err != nil {
+		return nil, errors.New("failed to load price rules")
+	}
+
+	query := database.DB.Where("salon_id = ? AND is_active = ?", service.SalonID, true)
+	if couponCode != "" {
+		query = query.Where("(code = '' OR code = ?)", couponCode)
+	} else {
+		query = query.Where("code = ''")
+	}
+	if err := query.Order("id").Find(&list.promotions).Error; err != nil {
+		return nil, errors.New("failed to load promotions")
+	}
+
+	return list, nil
+}
+
+// listPrice Price of the service with a staff member at a start time, before promotions
+func (l *priceList) listPrice(staff models.Staff, start time.Time) []pricing.Line {
+	lines := []pricing.Line{{Type: pricing.LineService, Label: l.service.Name, Amount: l.service.Price, Reference: l.service.ID}}
+
+	level := staff.PriceLevel()
+	local := start.In(salonLocation)
+	for _, rule := range l.rules {
+		if rule.StaffLevel != "" && rule.StaffLevel != level {
+			continue
+		}
+		if !priceRuleWindow(rule).Contains(local) || !containsID(rule.ServiceIDs, l.service.ID) {
+			continue
+		}
+		// Percentages are taken on the service price so rules do not compound
+		amount := priceRuleAdjustment(rule).Amount(l.service.Price)
+		if amount == 0 {
+			continue
+		}
+		lineType := pricing.LineSurcharge
+		if rule.StaffLevel != "" {
+			lineType = pricing.LineStaffLevel
+		}
+		lines = append(lines, pricing.Line{Type: lineType, Label: rule.Name, Amount: amount, Reference: rule.ID})
+	}
+
+	return lines
+}
+
+// quote Price shown before booking: the list price and the best automatic promotion open to every customer.
+// It is an estimate when promotions depending on the customer (first visits, uses per customer) also apply
+// at that time; those can only lower what the customer is charged.
+func (l *priceList) quote(staff models.Staff, start time.Time) ([]pricing.Line, bool) {
+	lines := l.listPrice(staff, start)
+	total := pricing.Total(lines)
+
+	var best *models.Promotion
+	estimate := false
+	for i := range l.promotions {
+		promotion := &l.promotions[i]
+		if promotion.Code != "" || !promotionMatches(*promotion, l.service.ID, start) {
+			continue
+		}
+		if promotion.FirstVisitOnly || promotion.MaxUsesPerCustomer != nil {
+			estimate = true
+			continue
+		}
+		if best == nil || promotionDiscount(*promotion).Amount(total) > promotionDiscount(*best).Amount(total) {
+			best = promotion
+		}
+	}
+
+	lines, _ = applyPromotion(lines, best, pricing.LinePromotion)
+	return lines, estimate
+}
+
+// applyPromotion Add a promotion's discount on the current total. Returns the discount given.
+func applyPromotion(lines []pricing.Line, promotion *models.Promotion, lineType string) ([]pricing.Line, int) {
+	if promotion == nil {
+		return lines, 0
+	}
+	discount := promotionDiscount(*promotion).Amount(pricing.Total(lines))
+	if discount == 0 {
+		return lines, 0
+	}
+	return append(lines, pricing.Line{Type: lineType, Label: promotion.Name, Amount: -discount, Reference: promotion.ID}), discount
+}
+
+// priceReservation Set TotalPrice and PriceBreakdown from the service price, the salon's price rules,
+// the best automatic promotion, the coupon in CouponCode and the points in PointsUsed. Returns the promotion uses to record with the reservation.
+func priceReservation(reservation *models.Reservation) ([]models.PromotionRedemption, error) {
+	var service models.Service
+	if err := database.DB.Where("id = ? AND salon_id = ?", reservation.ServiceID, reservation.SalonID).First(&service).Error; err != nil {
+		return nil, errors.New("service not found")
+	}
+
+	var staff models.Staff
+	if err := database.DB.Where("id = ? AND salon_id = ?", reservation.StaffID, reservation.SalonID).First(&staff).Error; err != nil {
+		return nil, errors.New("staff not found")
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(reservation.CouponCode))
+	list, err := loadPriceList(service, code)
+	if err != nil {
+		return nil, err
+	}
+	lines := list.listPrice(staff, reservation.StartTime)
+	total := pricing.Total(lines)
+
+	// Best automatic promotion, then the coupon on what is left
+	var automatic, coupon *models.Promotion
+	for i := range list.promotions {
+		promotion := &list.promotions[i]
+		applies, err := promotionApplies(*promotion, *reservation)
+		if err != nil {
+			return nil, errors.New("failed to check promotions")
+		}
+		if promotion.Code != "" {
+			if !applies {
+				return nil, errCouponNotApplicable
+			}
+			coupon = promotion
+			continue
+		}
+		if applies && (automatic == nil || promotionDiscount(*promotion).Amount(total) > promotionDiscount(*automatic).Amount(total)) {
+			automatic = promotion
+		}
+	}
+	if code != "" && coupon == nil {
+		return nil, errInvalidCoupon
+	}
+
+	var redemptions []models.PromotionRedemption
+	for _, applied := range []struct {
+		promotion *models.Promotion
+		lineType  string
+	}{{automatic, pricing.LinePromotion}, {coupon, pricing.LineCoupon}} {
+		var discount int
+		lines, discount = applyPromotion(lines, applied.promotion, applied.lineType)
+		if discount == 0 {
+			continue
+		}
+		redemptions = append(redemptions, models.PromotionRedemption{
+			PromotionID: applied.promotion.ID,
+			UserID:      reservation.UserID,
+			GuestEmail:  reservation.GuestEmail,
+			Amount:      discount,
+		})
+	}
+
+	// Loyalty points are spent last, on what is left to pay
+	if reservation.PointsUsed < 0 {
+		return nil, errors.New("points_used must not be negative")
+	}
+	if reservation.PointsUsed > 0 {
+		if reservation.UserID == nil {
+			return nil, errors.New("points can only be used with an account")
+		}
+		available, err := availablePoints(*reservation.UserID, time.Now())
+		if err != nil {
+			return nil, errors.New("failed to check points")
+		}
+		if reservation.PointsUsed > available {
+			return nil, loyalty.ErrInsufficientPoints
+		}
+		reservation.PointsUsed = loyaltyProgram.Redeemable(reservation.PointsUsed, pricing.Total(lines))
+		if reservation.PointsUsed > 0 {
+			lines = append(lines, pricing.Line{Type: pricing.LinePoints, Label: "Points", Amount: -loyaltyProgram.Value(reservation.PointsUsed)})
+		}
+	}
+
+	reservation.PriceBreakdown = priceBreakdown(lines)
+	reservation.TotalPrice = pricing.Total(lines)
+	reservation.CouponCode = ""
+	return redemptions, nil
+}
+
+// priceBreakdown Breakdown stored with a reservation
+func priceBreakdown(lines []pricing.Line) []models.PriceLine {
+	breakdown := make([]models.PriceLine, len(lines))
+	for i, line := range lines {
+		breakdown[i] = models.PriceLine{Type: line.Type, Label: line.Label, Amount: line.Amount, Reference: line.Reference}
+	}
+	return breakdown
+}
+
+// priceRuleAdjustment Price change of a price rule
+func priceRuleAdjustment(rule models.PriceRule) pricing.Adjustment {
+	return pricing.Adjustment{Type: rule.AdjustmentType, Value: rule.AdjustmentValue}
+}
+
+// priceRuleWindow Days and times a price rule applies to
+func priceRuleWindow(rule models.PriceRule) pricing.Window {
+	return pricing.Window{Weekdays: rule.Weekdays, StartTime: rule.StartTime, EndTime: rule.EndTime}
+}
+
+// promotionDiscount Discount of a promotion
+func promotionDiscount(promotion models.Promotion) pricing.Discount {
+	return pricing.Discount{Type: promotion.DiscountType, Value: promotion.DiscountValue}
+}
+
+// promotionWindow Days and times a promotion applies to
+func promotionWindow(promotion models.Promotion) pricing.Window {
+	return pricing.Window{Weekdays: promotion.Weekdays, StartTime: promotion.StartTime, EndTime: promotion.EndTime}
+}
+
+// promotionMatches Check a promotion's service, time and total usage rules
+func promotionMatches(promotion models.Promotion, serviceID uint, start time.Time) bool {
+	if !promotionWindow(promotion).Contains(start.In(salonLocation)) {
+		return false
+	}
+	if (promotion.ValidFrom != nil && start.Before(*promotion.ValidFrom)) ||
+		(promotion.ValidUntil != nil && !start.Before(*promotion.ValidUntil)) {
+		return false
+	}
+	if !containsID(promotion.ServiceIDs, serviceID) {
+		return false
+	}
+	return promotion.MaxUses == nil || promotion.UsedCount < *promotion.MaxUses
+}
+
+// promotionApplies Check a promotion's rules against a reservation
+func promotionApplies(promotion models.Promotion, reservation models.Reservation) (bool, error) {
+	if !promotionMatches(promotion, reservation.ServiceID, reservation.StartTime) {
+		return false, nil
+	}
+
+	if !promotion.FirstVisitOnly && promotion.MaxUsesPerCustomer == nil {
+		return true, nil
+	}
+
+	// Customer-specific rules need to know who is booking
+	condition, customer := customerCondition(reservation)
+	if condition == "" {
+		return false, nil
+	}
+
+	if promotion.FirstVisitOnly {
+		var visits int64
+		if err := database.DB.Model(&models.Reservation{}).
+			Where("salon_id = ? AND status != ? AND id <> ?", reservation.SalonID, "cancelled", reservation.ID).
+			Where(condition, customer).
+			Count(&visits).Error; err != nil {
+			return false, err
+		}
+		if visits > 0 {
+			return false, nil
+		}
+	}
+
+	if promotion.MaxUsesPerCustomer != nil {
+		var uses int64
+		if err := database.DB.Model(&models.PromotionRedemption{}).
+			Where("promotion_id = ? AND reservation_id <> ?", promotion.ID, reservation.ID).
+			Where(condition, customer).
+			Count(&uses).Error; err != nil {
+			return false, err
+		}
+		if uses >= int64(*promotion.MaxUsesPerCustomer) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// containsID Check whether an ID list allows an ID (an empty list allows every ID)
+func containsID(ids []uint, id uint) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// customerCondition Condition matching the customer of a reservation (an account, or a guest by email)
+func customerCondition(reservation models.Reservation) (string, interface{}) {
+	if reservation.UserID != nil {
+		return "user_id = ?", *reservation.UserID
+	}
+	if reservation.GuestEmail != "" {
+		return "LOWER(guest_email) = LOWER(?)", reservation.GuestEmail
+	}
+	return "", nil
+}
+
+// redeemPromotions Record the promotion uses of a reservation, enforcing the total and per-customer usage limits.
+// Each promotion row stays locked until the transaction ends, so concurrent bookings are checked one after the other.
+func redeemPromotions(tx *gorm.DB, reservation models.Reservation, redemptions []models.PromotionRedemption) error {
+	for _, redemption := range redemptions {
+		var promotion models.Promotion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promotion, redemption.PromotionID).Error; err != nil {
+			return errPromotionUnavailable
+		}
+		if promotion.MaxUses != nil && promotion.UsedCount >= *promotion.MaxUses {
+			return errPromotionUnavailable
+		}
+
+		if promotion.MaxUsesPerCustomer != nil {
+			condition, customer := customerCondition(reservation)
+			if condition == "" {
+				return errPromotionUnavailable
+			}
+			var uses int64
+			if err := tx.Model(&models.PromotionRedemption{}).
+				Where("promotion_id = ? AND reservation_id <> ?", promotion.ID, reservation.ID).
+				Where(condition, customer).
+				Count(&uses).Error; err != nil {
+				return err
+			}
+			if uses >= int64(*promotion.MaxUsesPerCustomer) {
+				return errPromotionUnavailable
+			}
+		}
+
+		if err := tx.Model(&promotion).UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+			return err
+		}
+
+		redemption.ReservationID = reservation.ID
+		if err := tx.Create(&redemption).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releasePromotions Give back the promotion uses of a cancelled reservation
+func releasePromotions(tx *gorm.DB, reservationID uint) error {
+	var redemptions []models.PromotionRedemption
+	if err := tx.Where("reservation_id = ?", reservationID).Find(&redemptions).Error; err != nil {
+		return err
+	}
+
+	for _, redemption := range redemptions {
+		if err := tx.Model(&models.Promotion{}).
+			Where("id = ? AND used_count > 0", redemption.PromotionID).
+			UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&redemption).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/services/pricing"
+)
+
+func TestQuote(t *testing.T) {
+	service := models.Service{ID: 1, Name: "Cut", Price: 5000}
+	start := time.Date(2026, 1, 5, 11, 0, 0, 0, salonLocation)
+	once := 1
+
+	automatic := models.Promotion{ID: 1, Name: "Winter", DiscountType: pricing.DiscountFixed, DiscountValue: 500}
+	better := models.Promotion{ID: 2, Name: "Weekday", DiscountType: pricing.DiscountPercent, DiscountValue: 20}
+	firstVisit := models.Promotion{ID: 3, Name: "Welcome", DiscountType: pricing.DiscountPercent, DiscountValue: 30, FirstVisitOnly: true}
+	perCustomer := models.Promotion{ID: 4, Name: "Once", DiscountType: pricing.DiscountFixed, DiscountValue: 2000, MaxUsesPerCustomer: &once}
+	coupon := models.Promotion{ID: 5, Name: "Coupon", Code: "SAVE", DiscountType: pricing.DiscountFixed, DiscountValue: 3000}
+	later := models.Promotion{ID: 6, Name: "Spring", DiscountType: pricing.DiscountPercent, DiscountValue: 30, FirstVisitOnly: true, ServiceIDs: []uint{2}}
+
+	tests := []struct {
+		name         string
+		promotions   []models.Promotion
+		wantTotal    int
+		wantEstimate bool
+	}{
+		{"list price", nil, 5000, false},
+		{"best automatic promotion", []models.Promotion{automatic, better}, 4000, false},
+		{"coupons left out", []models.Promotion{automatic, coupon}, 4500, false},
+		{"first visit promotion", []models.Promotion{automatic, firstVisit}, 4500, true},
+		{"promotion limited per customer", []models.Promotion{perCustomer}, 5000, true},
+		{"customer promotion for another service", []models.Promotion{automatic, later}, 4500, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := &priceList{service: service, promotions: tt.promotions}
+			lines, estimate := list.quote(models.Staff{ID: 1}, start)
+			if total := pricing.Total(lines); total != tt.wantTotal || estimate != tt.wantEstimate {
+				t.Errorf("quote() = %d, estimate %v, want %d, estimate %v", total, estimate, tt.wantTotal, tt.wantEstimate)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPromotions Get a salon's promotions and coupons
+func GetPromotions(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.Promotion{}).Where("salon_id = ?", salonID)
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at", Desc: true}}, "id", req, func(promotion models.Promotion) uint { return promotion.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promotions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreatePromotion Create a promotion (automatic when no code is given)
+func CreatePromotion(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var promotion models.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promotion.ID = 0
+	promotion.SalonID = salonID
+	promotion.UsedCount = 0
+
+	// Rule violations are reported by the model hook
+	if err := database.DB.Create(&promotion).Error; err != nil {
+		savePromotionError(c, err)
+		return
+	}
+	invalidateSlots(salonID)
+
+	c.JSON(http.StatusCreated, promotion)
+}
+
+// UpdatePromotion Update a promotion
+func UpdatePromotion(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var promotion models.Promotion
+	if err := database.DB.Where("id = ? AND salon_id = ?", c.Param("promotion_id"), salonID).First(&promotion).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+		return
+	}
+
+	id, usedCount := promotion.ID, promotion.UsedCount
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	promotion.ID, promotion.SalonID, promotion.UsedCount = id, salonID, usedCount
+
+	if err := database.DB.Save(&promotion).Error; err != nil {
+		savePromotionError(c, err)
+		return
+	}
+	invalidateSlots(salonID)
+
+	c.JSON(http.StatusOK, promotion)
+}
+
+// DeletePromotion Delete a promotion (reservations keep their price breakdown)
+func DeletePromotion(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND salon_id = ?", c.Param("promotion_id"), salonID).Delete(&models.Promotion{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete promotion"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+		return
+	}
+	invalidateSlots(salonID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted successfully"})
+}
+
+// savePromotionError Respond to a failed promotion save: a coupon code taken by another of the
+// salon's promotions (the unique index) is a conflict, anything else a rule violation from the hook
+func savePromotionError(c *gin.Context, err error) {
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "coupon code is already in use"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/notification"
+	"reservation-platform-sample/internal/services/reminder"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// manageLinkBaseURL Frontend page that confirms or cancels a reservation from a manage link
+var manageLinkBaseURL = "http://localhost:3000/reservations/manage" // Should be obtained from environment variables
+
+var errReminderSkipped = errors.New("reservation is no longer confirmed")
+
+// RunReminderScheduler Send due reminders every interval until the context ends.
+// Several server instances can run it at once; each reminder is claimed by one of them.
+func RunReminderScheduler(ctx context.Context, offsets []time.Duration, interval time.Duration) {
+	if len(offsets) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := sendDueReminders(ctx, offsets, time.Now()); err != nil {
+			log.Printf("Failed to send reminders: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDueReminders Send the reminders due at a time (offsets are largest first)
+func sendDueReminders(ctx context.Context, offsets []time.Duration, now time.Time) error {
+	var reservations []models.Reservation
+	if err := database.DB.Where("status = ? AND start_time > ? AND start_time <= ?", "confirmed", now, now.Add(offsets[0])).
+		Order("start_time").Find(&reservations).Error; err != nil {
+		return err
+	}
+	if len(reservations) == 0 {
+		return nil
+	}
+
+	// Skip reminders already claimed without hitting the unique index for each of them
+	ids := make([]uint, len(reservations))
+	for i, reservation := range reservations {
+		ids[i] = reservation.ID
+	}
+	var deliveries []models.ReminderDelivery
+	if err := database.DB.Where("reservation_id IN ?", ids).Find(&deliveries).Error; err != nil {
+		return err
+	}
+	type claimKey struct {
+		reservationID uint
+		offsetMinutes int
+		startTime     int64
+	}
+	claimed := make(map[claimKey]models.ReminderDelivery, len(deliveries))
+	for _, delivery := range deliveries {
+		claimed[claimKey{delivery.ReservationID, delivery.OffsetMinutes, delivery.StartTime.Unix()}] = delivery
+	}
+
+	for _, reservation := range reservations {
+		offset, ok := reminder.Due(offsets, reservation.StartTime, reservation.CreatedAt, now)
+		if !ok {
+			continue
+		}
+		delivery := models.ReminderDelivery{
+			ReservationID: reservation.ID,
+			OffsetMinutes: int(offset / time.Minute),
+			StartTime:     reservation.StartTime,
+			Attempts:      1,
+			ClaimedAt:     now,
+		}
+
+		// Another instance may claim the same reminder (or the same retry) first
+		var result *gorm.DB
+		if previous, ok := claimed[claimKey{delivery.ReservationID, delivery.OffsetMinutes, delivery.StartTime.Unix()}]; ok {
+			// Failed and abandoned sends are retried while this offset is still the one due
+			claim := reminder.Claim{
+				Sent:      previous.SentAt != nil,
+				Failed:    previous.Error != "",
+				Attempts:  previous.Attempts,
+				ClaimedAt: previous.ClaimedAt,
+			}
+			if !claim.Retry(now) {
+				continue
+			}
+			delivery = previous
+			delivery.Attempts++
+			delivery.ClaimedAt = now
+			result = database.DB.Model(&models.ReminderDelivery{}).
+				Where("id = ? AND sent_at IS NULL AND attempts = ?", previous.ID, previous.Attempts).
+				Updates(map[string]interface{}{"attempts": delivery.Attempts, "error": "", "claimed_at": now})
+		} else {
+			result = database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&delivery)
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		sentAt := time.Now()
+		updates := map[string]interface{}{"sent_at": &sentAt}
+		if err := sendReminder(ctx, reservation.ID); err != nil {
+			updates = map[string]interface{}{"error": err.Error()}
+			if !errors.Is(err, errReminderSkipped) {
+				log.Printf("Failed to send reminder for reservation %d (attempt %d): %v", reservation.ID, delivery.Attempts, err)
+			}
+		}
+		// A run that took the claim over after the lease owns the outcome
+		if err := database.DB.Model(&delivery).Where("attempts = ?", delivery.Attempts).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendReminder Send a reminder with confirm and cancel links, unless the reservation was cancelled meanwhile
+func sendReminder(ctx context.Context, reservationID uint) error {
+	var reservation models.Reservation
+	if err := database.DB.First(&reservation, reservationID).Error; err != nil {
+		return err
+	}
+	if reservation.Status != "confirmed" {
+		return errReminderSkipped
+	}
+
+	customer, data, _, err := reservationNotification(reservation)
+	if err != nil {
+		return err
+	}
+
+	token, err := generateManageToken(reservation)
+	if err != nil {
+		return err
+	}
+	data.ConfirmURL = manageLink(token, "confirm")
+	data.CancelURL = manageLink(token, "cancel")
+
+	_, err = notifier.Notify(ctx, customer, notification.EventReservationReminder, data)
+	return err
+}
+
+// manageLink Frontend link acting on a reservation with a manage token
+func manageLink(token, action string) string {
+	query := url.Values{"token": {token}, "action": {action}}
+	return manageLinkBaseURL + "?" + query.Encode()
+}
@@ -2,20 +2,25 @@ package handlers
 
 import (
 	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"reservation-platform-sample/internal/domain/models"
 	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/loyalty"
+	"reservation-platform-sample/internal/services/notification"
+	"reservation-platform-sample/internal/services/pricing"
+	"reservation-platform-sample/internal/services/webhook"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // GetReservations Get reservation list
 func GetReservations(c *gin.Context) {
-	var reservations []models.Reservation
-
 	// Get user ID from context (set by authentication middleware)
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -23,16 +28,28 @@ func GetReservations(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Where("user_id = ?", userID).
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Latest appointment first
+	query := database.DB.Model(&models.Reservation{}).Where("user_id = ?", userID).
 		Preload("Salon").
 		Preload("Staff").
-		Preload("Service").
-		Find(&reservations).Error; err != nil {
+		Preload("Service")
+	page, err := fetchPage(query, []sortKey{{Expr: "start_time", Desc: true}}, "id", req, func(reservation models.Reservation) uint { return reservation.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reservations"})
 		return
 	}
 
-	c.JSON(http.StatusOK, reservations)
+	c.JSON(http.StatusOK, page)
 }
 
 // GetReservation Get reservation details
@@ -50,6 +67,7 @@ func GetReservation(c *gin.Context) {
 		Preload("Salon").
 		Preload("Staff").
 		Preload("Service").
+		Preload("Payments").
 		First(&reservation).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
 		return
@@ -58,22 +76,58 @@ func GetReservation(c *gin.Context) {
 	c.JSON(http.StatusOK, reservation)
 }
 
+type ReservationRequest struct {
+	SalonID       uint      `json:"salon_id" binding:"required"`
+	StaffID       uint      `json:"staff_id" binding:"required"`
+	ServiceID     uint      `json:"service_id" binding:"required"`
+	StartTime     time.Time `json:"start_time" binding:"required"`
+	Notes         string    `json:"notes"`
+	CouponCode    string    `json:"coupon_code"`
+	PointsUsed    int       `json:"points_used" binding:"min=0"`
+	GiftCardCode  string    `json:"gift_card_code"`
+	PaymentMethod string    `json:"payment_method"` // Gateway token, required when the salon takes a deposit or prepayment
+	HoldToken     string    `json:"hold_token"`     // Slot hold taken while filling in the form
+}
+
+// UpdateReservationRequest Changes a customer can make to their booking
+type UpdateReservationRequest struct {
+	StaffID   uint      `json:"staff_id" binding:"required"`
+	ServiceID uint      `json:"service_id" binding:"required"`
+	StartTime time.Time `json:"start_time" binding:"required"`
+	Notes     string    `json:"notes"`
+}
+
 // CreateReservation Create reservation
 func CreateReservation(c *gin.Context) {
-	var reservation models.Reservation
-
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	if err := c.ShouldBindJSON(&reservation); err != nil {
+	var req ReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	reservation.UserID = userID.(uint)
+	// Status, check-in and completion are only ever set by the salon
+	uid := userID.(uint)
+	reservation := models.Reservation{
+		SalonID:         req.SalonID,
+		StaffID:         req.StaffID,
+		UserID:          &uid,
+		ServiceID:       req.ServiceID,
+		ReservationDate: dayRange(req.StartTime.In(salonLocation)).Start,
+		StartTime:       req.StartTime,
+		Status:          "confirmed",
+		Notes:           req.Notes,
+		CouponCode:      req.CouponCode,
+		PointsUsed:      req.PointsUsed,
+		GiftCardCode:    req.GiftCardCode,
+		PaymentMethod:   req.PaymentMethod,
+		HoldToken:       req.HoldToken,
+	}
 
 	// Validate reservation
 	if err := validateReservation(&reservation); err != nil {
@@ -81,13 +135,43 @@ func CreateReservation(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Create(&reservation).Error; err != nil {
+	// Price is computed server-side from the service, the salon's promotions and the points used
+	redemptions, err := priceReservation(&reservation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := applyGiftCard(&reservation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Deposit or prepayment required by the salon
+	charge, ok := chargeReservation(c, &reservation)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockStaffSchedule(tx, &reservation); err != nil {
+			return err
+		}
+		return insertReservation(tx, &reservation, redemptions, charge)
+	}); err != nil {
+		releaseCharge(charge)
+		var rejected *bookingError
+		if errors.As(err, &rejected) || errors.Is(err, errPromotionUnavailable) || errors.Is(err, loyalty.ErrInsufficientPoints) || errors.Is(err, errGiftCardUnavailable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reservation"})
 		return
 	}
 
 	// Return the created reservation with related data
-	database.DB.Preload("Salon").Preload("Staff").Preload("Service").First(&reservation, reservation.ID)
+	database.DB.Preload("Salon").Preload("Staff").Preload("Service").Preload("Payments").First(&reservation, reservation.ID)
+
+	go notifyReservation(notification.EventReservationCreated, reservation)
 
 	c.JSON(http.StatusCreated, reservation)
 }
@@ -108,23 +192,145 @@ func UpdateReservation(c *gin.Context) {
 		return
 	}
 
-	if err := c.ShouldBindJSON(&reservation); err != nil {
+	var req UpdateReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Validate reservation
-	if err := validateReservation(&reservation); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	// Prices come from the salon's rules, never from the client
+	changed := reservation
+	changed.StaffID = req.StaffID
+	changed.ServiceID = req.ServiceID
+	changed.StartTime = req.StartTime
+	changed.Notes = req.Notes
+
+	if err := changeReservation(reservation, &changed); err != nil {
+		var rejected *bookingError
+		if errors.As(err, &rejected) {
+			c.JSON(rejected.status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reservation"})
 		return
 	}
 
-	if err := database.DB.Save(&reservation).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reservation"})
-		return
+	c.JSON(http.StatusOK, changed)
+}
+
+// bookingError Change refused by the booking rules, pricing or the reservation's state, reported to the client as is
+type bookingError struct {
+	status int
+	err    error
+}
+
+func (e *bookingError) Error() string {
+	return e.err.Error()
+}
+
+func (e *bookingError) Unwrap() error {
+	return e.err
+}
+
+// changeReservation Save a confirmed reservation moved to another staff member, service or time (or with new notes):
+// check the booking rules, price it again, record the webhook event and live change, and notify the customer.
+// Refused changes are returned as *bookingError.
+func changeReservation(booked models.Reservation, reservation *models.Reservation) error {
+	// Checked-in, completed and cancelled bookings only change through the salon or cancellation
+	if booked.Status != "confirmed" {
+		return &bookingError{http.StatusConflict, errors.New("Only confirmed reservations can be changed")}
 	}
 
-	c.JSON(http.StatusOK, reservation)
+	reservation.ReservationDate = dayRange(reservation.StartTime.In(salonLocation)).Start
+
+	// A new time needs a new attendance confirmation
+	reservation.CustomerConfirmedAt = booked.CustomerConfirmedAt
+	if !reservation.StartTime.Equal(booked.StartTime) {
+		reservation.CustomerConfirmedAt = nil
+	}
+
+	// Calendar clients replace their copy of the event with the higher sequence
+	reservation.CalendarSequence = booked.CalendarSequence + 1
+
+	if err := validateReservation(reservation); err != nil {
+		return &bookingError{http.StatusBadRequest, err}
+	}
+
+	// The stylist, menu and time decide the price, so changing them prices the booking again
+	repriced := reservation.StaffID != booked.StaffID || reservation.ServiceID != booked.ServiceID || !reservation.StartTime.Equal(booked.StartTime)
+	var redemptions []models.PromotionRedemption
+	if repriced {
+		prepaid, err := paidInAdvance(booked)
+		if err != nil {
+			return err
+		}
+		if prepaid {
+			return &bookingError{http.StatusConflict, errors.New("Reservations paid in advance, with points or with a gift card can't be changed; cancel and book again")}
+		}
+
+		// The coupon the booking was made with still applies
+		if reservation.CouponCode, err = redeemedCouponCode(booked.ID); err != nil {
+			return err
+		}
+		if redemptions, err = priceReservation(reservation); err != nil {
+			return &bookingError{http.StatusBadRequest, err}
+		}
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockStaffSchedule(tx, reservation); err != nil {
+			return err
+		}
+		if err := tx.Omit("Payments").Save(reservation).Error; err != nil {
+			return err
+		}
+		if repriced {
+			if err := releasePromotions(tx, reservation.ID); err != nil {
+				return err
+			}
+			if err := redeemPromotions(tx, *reservation, redemptions); err != nil {
+				return err
+			}
+		}
+		return enqueueReservationEvent(tx, webhook.EventReservationUpdated, *reservation)
+	}); err != nil {
+		if errors.Is(err, errPromotionUnavailable) {
+			return &bookingError{http.StatusConflict, err}
+		}
+		return err
+	}
+
+	// The live listener drops the cache too, but the next request shouldn't wait for it
+	invalidateSlots(reservation.SalonID)
+
+	go notifyReservation(notification.EventReservationUpdated, *reservation)
+
+	return nil
+}
+
+// paidInAdvance Whether money was taken for the booked price: a deposit or prepayment, points or a gift card
+func paidInAdvance(reservation models.Reservation) (bool, error) {
+	if reservation.PointsUsed > 0 || reservation.GiftCardID != nil {
+		return true, nil
+	}
+	var payments int64
+	if err := database.DB.Model(&models.Payment{}).Where("reservation_id = ?", reservation.ID).Count(&payments).Error; err != nil {
+		return false, err
+	}
+	return payments > 0, nil
+}
+
+// redeemedCouponCode Code of the coupon a reservation was booked with (empty when none)
+func redeemedCouponCode(reservationID uint) (string, error) {
+	var codes []string
+	err := database.DB.Model(&models.Promotion{}).
+		Joins("JOIN promotion_redemptions pr ON pr.promotion_id = promotions.id").
+		Where("pr.reservation_id = ? AND promotions.code <> ''", reservationID).
+		Pluck("promotions.code", &codes).Error
+	if err != nil || len(codes) == 0 {
+		return "", err
+	}
+	return codes[0], nil
 }
 
 // DeleteReservation Cancel reservation
@@ -143,20 +349,33 @@ func DeleteReservation(c *gin.Context) {
 		return
 	}
 
-	// Change reservation status to cancelled
+	// Change reservation status to cancelled and give back the promotions it used
 	reservation.Status = "cancelled"
-	if err := database.DB.Save(&reservation).Error; err != nil {
+	if err := cancelReservation(&reservation); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel reservation"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Reservation cancelled successfully"})
+	// Refund per the salon's cancellation policy once the cancellation is saved (failures are retried later)
+	refunded, refundErr := settleRefunds(c.Request.Context(), reservation.ID)
+	if refundErr != nil {
+		log.Printf("Failed to refund reservation %d: %v", reservation.ID, refundErr)
+	}
+
+	go notifyReservation(notification.EventReservationCancelled, reservation)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reservation cancelled successfully", "refunded_amount": refunded, "refund_pending": refundErr != nil})
 }
 
 // GetAvailableSlots Get available time slots
 func GetAvailableSlots(c *gin.Context) {
-	salonID := c.Param("salon_id")
+	salonID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid salon ID"})
+		return
+	}
 	staffID := c.Query("staff_id")
+	serviceID := c.Query("service_id")
 	date := c.Query("date")
 
 	if date == "" {
@@ -164,80 +383,271 @@ func GetAvailableSlots(c *gin.Context) {
 		return
 	}
 
-	parsedDate, err := time.Parse("2006-01-02", date)
+	parsedDate, err := time.ParseInLocation("2006-01-02", date, salonLocation)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
 		return
 	}
 
-	slots, err := getAvailableSlots(salonID, staffID, parsedDate)
+	slots, err := availableSlotsJSON(uint(salonID), staffID, serviceID, parsedDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"slots": slots})
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(slots))
+}
+
+// availableSlots Slot times of a day and, with a service, each free staff member with the price they would charge
+func availableSlots(salonID, staffID, serviceID string, date time.Time) (gin.H, error) {
+	options, service, err := findSlotOptions(salonID, staffID, serviceID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := slotTimes(options)
+	if serviceID == "" {
+		return gin.H{"slots": slots}, nil
+	}
+
+	list, err := loadPriceList(service, "")
+	if err != nil {
+		return nil, err
+	}
+
+	priced := make([]gin.H, 0, len(options))
+	for _, option := range options {
+		lines, estimate := list.quote(option.Staff, option.Start)
+		priced = append(priced, gin.H{
+			"start_time":        option.Start.Format("15:04"),
+			"staff_id":          option.Staff.ID,
+			"staff_name":        option.Staff.Name,
+			"level":             option.Staff.PriceLevel(),
+			"price":             pricing.Total(lines),
+			"price_is_estimate": estimate,
+			"price_breakdown":   lines,
+		})
+	}
+
+	return gin.H{"slots": slots, "options": priced}, nil
 }
 
 // validateReservation Validate reservation
 func validateReservation(reservation *models.Reservation) error {
+	if err := prepareReservation(reservation); err != nil {
+		return err
+	}
+
+	return checkBookingRules(reservation)
+}
+
+// insertReservation Save a new reservation with its booking charge, promotion uses, redeemed points and gift card payment,
+// using up its slot hold
+func insertReservation(tx *gorm.DB, reservation *models.Reservation, redemptions []models.PromotionRedemption, charge *models.Payment) error {
+	// Payments come from the gateway, never from the request body
+	if err := tx.Omit("Payments").Create(reservation).Error; err != nil {
+		return err
+	}
+
+	if charge != nil {
+		charge.ReservationID = reservation.ID
+		if err := tx.Create(charge).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := redeemPromotions(tx, *reservation, redemptions); err != nil {
+		return err
+	}
+	if err := redeemPoints(tx, *reservation); err != nil {
+		return err
+	}
+	if err := redeemGiftCard(tx, *reservation); err != nil {
+		return err
+	}
+	// The slot hold has served its purpose
+	if reservation.HoldToken != "" {
+		if err := tx.Where("token = ?", reservation.HoldToken).Delete(&models.SlotHold{}).Error; err != nil {
+			return err
+		}
+	}
+	return enqueueReservationEvent(tx, webhook.EventReservationCreated, *reservation)
+}
+
+// cancelReservation Save a cancelled reservation, release the promotion uses, points and gift card balance it held
+// and record the refunds its payments are owed
+func cancelReservation(reservation *models.Reservation) error {
+	reservation.CalendarSequence++
+	cancelledAt := time.Now()
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Payments").Save(reservation).Error; err != nil {
+			return err
+		}
+		if err := releasePromotions(tx, reservation.ID); err != nil {
+			return err
+		}
+		if err := refundPoints(tx, *reservation); err != nil {
+			return err
+		}
+		if err := refundGiftCard(tx, *reservation, cancelledAt); err != nil {
+			return err
+		}
+		if err := scheduleRefunds(tx, *reservation, cancelledAt); err != nil {
+			return err
+		}
+		return enqueueReservationEvent(tx, webhook.EventReservationCancelled, *reservation)
+	})
+}
+
+// prepareReservation Derive end time, processing window and buffers from the salon configuration
+func prepareReservation(reservation *models.Reservation) error {
+	var salon models.Salon
+	if err := database.DB.First(&salon, reservation.SalonID).Error; err != nil {
+		return errors.New("salon not found")
+	}
+
+	var staff models.Staff
+	if err := database.DB.Where("id = ? AND salon_id = ?", reservation.StaffID, reservation.SalonID).First(&staff).Error; err != nil {
+		return errors.New("staff not found")
+	}
+
+	var service models.Service
+	if err := database.DB.Where("id = ? AND salon_id = ?", reservation.ServiceID, reservation.SalonID).First(&service).Error; err != nil {
+		return errors.New("service not found")
+	}
+
+	applyServicePhases(reservation, service)
+	applyBuffers(reservation, salon, staff, service)
+
+	return nil
+}
+
+// checkBookingRules Check the booking rules (can be overridden by salon admins)
+func checkBookingRules(reservation *models.Reservation) error {
 	// Check for past date/time
 	if reservation.StartTime.Before(time.Now()) {
 		return errors.New("cannot book past dates")
 	}
 
-	// Check for double booking
-	var existingReservation models.Reservation
-	err := database.DB.Where(
-		"staff_id = ? AND reservation_date = ? AND status != 'cancelled' AND ((start_time <= ? AND end_time > ?) OR (start_time < ? AND end_time >= ?))",
+	// Check for double booking (the staff member is free during processing phases)
+	candidate := reservationBusyRanges(*reservation)
+	busy, err := loadStaffBusyRanges(
 		reservation.StaffID,
-		reservation.ReservationDate,
-		reservation.StartTime,
-		reservation.StartTime,
-		reservation.EndTime,
-		reservation.EndTime,
-	).First(&existingReservation).Error
-
-	if err == nil {
+		timeRange{Start: candidate[0].Start, End: candidate[len(candidate)-1].End},
+		reservation.ID,
+		reservation.HoldToken,
+	)
+	if err != nil {
+		return errors.New("failed to check availability")
+	}
+
+	if conflicts(candidate, busy) {
 		return errors.New("time slot is already booked")
 	}
 
 	return nil
 }
 
+// lockStaffSchedule Lock the staff member's row so their bookings and holds are saved one at a time, then check the
+// booking rules again against everything committed before. Must run in the transaction saving the reservation;
+// a slot taken in the meantime is returned as a conflict *bookingError.
+func lockStaffSchedule(tx *gorm.DB, reservation *models.Reservation) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Staff{}, reservation.StaffID).Error; err != nil {
+		return err
+	}
+	if err := checkBookingRules(reservation); err != nil {
+		return &bookingError{http.StatusConflict, err}
+	}
+	return nil
+}
+
+// slotOption Staff member free to take a booking at a slot
+type slotOption struct {
+	Start time.Time
+	Staff models.Staff
+}
+
 // getAvailableSlots Calculate available time slots
-func getAvailableSlots(salonID, staffID string, date time.Time) ([]string, error) {
-	// Get staff working hours
-	var staff models.Staff
-	query := database.DB.Where("salon_id = ?", salonID)
+func getAvailableSlots(salonID, staffID, serviceID string, date time.Time) ([]string, error) {
+	options, _, err := findSlotOptions(salonID, staffID, serviceID, date)
+	if err != nil {
+		return nil, err
+	}
+	return slotTimes(options), nil
+}
+
+// slotTimes Distinct start times of slot options ("15:04")
+func slotTimes(options []slotOption) []string {
+	slots := []string{}
+	for _, option := range options {
+		slot := option.Start.Format("15:04")
+		if len(slots) == 0 || slots[len(slots)-1] != slot {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// findSlotOptions List every staff member free for the whole booking at each slot of the day.
+// Also returns the service used to size the booking.
+func findSlotOptions(salonID, staffID, serviceID string, date time.Time) ([]slotOption, models.Service, error) {
+	// Without a service the slot occupies the staff member for the default length
+	service := models.Service{DurationMinutes: defaultSlotMinutes}
+
+	var salon models.Salon
+	if err := database.DB.First(&salon, salonID).Error; err != nil {
+		return nil, service, err
+	}
+
+	// Get candidate staff
+	var staffMembers []models.Staff
+	query := database.DB.Where("salon_id = ? AND is_active = ?", salonID, true)
 	if staffID != "" {
 		query = query.Where("id = ?", staffID)
 	}
 
-	if err := query.First(&staff).Error; err != nil {
-		return nil, err
+	if err := query.Order("id").Find(&staffMembers).Error; err != nil {
+		return nil, service, err
 	}
 
-	// Get existing reservations for that day
-	var reservations []models.Reservation
-	err := database.DB.Where(
-		"staff_id = ? AND reservation_date = ? AND status != 'cancelled'",
-		staff.ID,
-		date,
-	).Find(&reservations).Error
+	if serviceID != "" {
+		if err := database.DB.Where("id = ? AND salon_id = ?", serviceID, salonID).First(&service).Error; err != nil {
+			return nil, service, err
+		}
+	}
 
-	if err != nil {
-		return nil, err
+	// Get existing bookings for that day per staff member
+	day := dayRange(date)
+	busyByStaff := make(map[uint][]timeRange, len(staffMembers))
+	for _, staff := range staffMembers {
+		busy, err := loadStaffBusyRanges(staff.ID, day, 0, "")
+		if err != nil {
+			return nil, service, err
+		}
+		busyByStaff[staff.ID] = busy
 	}
 
-	// Calculate available time slots (simplified version)
-	// In actual implementation, consider business hours, staff working hours, and existing reservations
-	var slots []string
-	for hour := 9; hour <= 17; hour++ {
-		timeSlot := strconv.Itoa(hour) + ":00"
-		// TODO: Check for overlap with existing reservations
-		slots = append(slots, timeSlot)
+	// A staff member is available when they can take the whole booking
+	// TODO: Consider business hours and staff working hours
+	options := []slotOption{}
+	now := time.Now()
+	dayStart := day.Start.Add(slotDayStartHour * time.Hour)
+	dayEnd := day.Start.Add(slotDayEndHour * time.Hour)
+	for start := dayStart; !start.Add(time.Duration(service.DurationMinutes) * time.Minute).After(dayEnd); start = start.Add(slotIntervalMinutes * time.Minute) {
+		if start.Before(now) {
+			continue
+		}
+
+		candidate := models.Reservation{StartTime: start}
+		applyServicePhases(&candidate, service)
+
+		for _, staff := range staffMembers {
+			applyBuffers(&candidate, salon, staff, service)
+			if !conflicts(reservationBusyRanges(candidate), busyByStaff[staff.ID]) {
+				options = append(options, slotOption{Start: start, Staff: staff})
+			}
+		}
 	}
 
-	return slots, nil
+	return options, service, nil
 }
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"reservation-platform-sample/internal/config"
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testDatabase Connect to and migrate the database in TEST_DATABASE_URL, skipping the test when it isn't set
+func testDatabase(t *testing.T) {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	if err := database.Connect(&config.Config{DatabaseURL: url}); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateReservationConcurrent(t *testing.T) {
+	testDatabase(t)
+	gin.SetMode(gin.TestMode)
+
+	suffix := time.Now().UnixNano()
+	salon := models.Salon{Name: "Concurrent Salon", Address: "Tokyo"}
+	if err := database.DB.Create(&salon).Error; err != nil {
+		t.Fatal(err)
+	}
+	staff := models.Staff{SalonID: salon.ID, Name: "Sato"}
+	service := models.Service{SalonID: salon.ID, Name: "Cut", Price: 5000, DurationMinutes: 60}
+	if err := database.DB.Create(&staff).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DB.Create(&service).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	const customers = 5
+	users := make([]models.User, customers)
+	for i := range users {
+		users[i] = models.User{Email: fmt.Sprintf("concurrent-%d-%d@example.com", suffix, i), PasswordHash: "-", Name: "Customer"}
+		if err := database.DB.Create(&users[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Cleanup(func() {
+		database.DB.Unscoped().Where("staff_id = ?", staff.ID).Delete(&models.Reservation{})
+		database.DB.Where("salon_id = ?", salon.ID).Delete(&models.OutboxEvent{})
+		for _, user := range users {
+			database.DB.Unscoped().Delete(&user)
+		}
+		database.DB.Unscoped().Delete(&service)
+		database.DB.Unscoped().Delete(&staff)
+		database.DB.Unscoped().Delete(&salon)
+	})
+
+	start := dayRange(time.Now().In(salonLocation)).Start.AddDate(0, 0, 7).Add(11 * time.Hour)
+	body, err := json.Marshal(ReservationRequest{SalonID: salon.ID, StaffID: staff.ID, ServiceID: service.ID, StartTime: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every customer asks for the same stylist and time at once
+	codes := make([]int, customers)
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func(i int, userID uint) {
+			defer wg.Done()
+			router := gin.New()
+			router.POST("/reservations", func(c *gin.Context) { c.Set("userID", userID) }, CreateReservation)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/reservations", bytes.NewReader(body)))
+			codes[i] = w.Code
+		}(i, user.ID)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusBadRequest, http.StatusConflict:
+		default:
+			t.Errorf("CreateReservation() status = %d, want 201, 400 or 409", code)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d concurrent bookings of the same slot succeeded, want 1 (statuses %v)", created, codes)
+	}
+
+	var booked int64
+	if err := database.DB.Model(&models.Reservation{}).Where("staff_id = ? AND status != 'cancelled'", staff.ID).Count(&booked).Error; err != nil {
+		t.Fatal(err)
+	}
+	if booked != 1 {
+		t.Errorf("%d reservations saved for the slot, want 1", booked)
+	}
+}
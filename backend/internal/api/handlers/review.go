@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+type CreateReviewRequest struct {
+	Rating      int    `json:"rating" binding:"required,min=1,max=5"`
+	StaffRating *int   `json:"staff_rating" binding:"omitempty,min=1,max=5"`
+	Comment     string `json:"comment" binding:"max=2000"`
+}
+
+type ReplyReviewRequest struct {
+	Reply string `json:"reply" binding:"required,max=2000"`
+}
+
+type FlagReviewRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type ModerateReviewRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+// CreateReview Review a completed reservation
+func CreateReview(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var reservation models.Reservation
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&reservation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	// Completion is recorded by the staff member when the visit ends
+	if reservation.Status != "completed" || reservation.CompletedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only completed reservations can be reviewed"})
+		return
+	}
+
+	var req CreateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review := models.Review{
+		ReservationID: reservation.ID,
+		UserID:        userID,
+		SalonID:       reservation.SalonID,
+		StaffID:       reservation.StaffID,
+		Rating:        req.Rating,
+		StaffRating:   req.StaffRating,
+		Comment:       req.Comment,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		return refreshRatings(tx, review.SalonID, review.StaffID)
+	})
+	if isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reservation has already been reviewed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
+		return
+	}
+	invalidateSalon(review.SalonID)
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// GetSalonReviews Get visible reviews of a salon
+func GetSalonReviews(c *gin.Context) {
+	listReviews(c, "salon_id = ?", c.Param("id"))
+}
+
+// GetStaffReviews Get visible reviews of a stylist
+func GetStaffReviews(c *gin.Context) {
+	listReviews(c, "staff_id = ?", c.Param("staff_id"))
+}
+
+// listReviews List visible reviews matching the condition
+func listReviews(c *gin.Context, condition string, value string) {
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.Review{}).
+		Where(condition, value).
+		Where("is_hidden = ?", false).
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Preload("Staff", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") })
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at", Desc: true}}, "id", req, func(review models.Review) uint { return review.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// FlagReview Report a review for moderation
+func FlagReview(c *gin.Context) {
+	var review models.Review
+	if err := database.DB.First(&review, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+
+	var req FlagReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review.IsFlagged = true
+	review.FlagReason = req.Reason
+	if err := database.DB.Save(&review).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to flag review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review reported successfully"})
+}
+
+// ReplyReview Reply to a review as the salon
+func ReplyReview(c *gin.Context) {
+	var review models.Review
+	if err := database.DB.First(&review, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+
+	if !canManageSalon(c, review.SalonID) {
+		return
+	}
+
+	var req ReplyReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	review.Reply = req.Reply
+	review.RepliedAt = &now
+	if err := database.DB.Save(&review).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reply to review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// GetFlaggedReviews Get reviews waiting for moderation
+func GetFlaggedReviews(c *gin.Context) {
+	if !requirePlatformAdmin(c) {
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Oldest report first
+	query := database.DB.Model(&models.Review{}).Where("is_flagged = ?", true)
+	page, err := fetchPage(query, []sortKey{{Expr: "updated_at"}}, "id", req, func(review models.Review) uint { return review.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// ModerateReview Hide or restore a review and clear its flag
+func ModerateReview(c *gin.Context) {
+	if !requirePlatformAdmin(c) {
+		return
+	}
+
+	var review models.Review
+	if err := database.DB.First(&review, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+
+	var req ModerateReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review.IsHidden = req.Hidden
+	review.IsFlagged = false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&review).Error; err != nil {
+			return err
+		}
+		return refreshRatings(tx, review.SalonID, review.StaffID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate review"})
+		return
+	}
+	invalidateSalon(review.SalonID)
+
+	c.JSON(http.StatusOK, review)
+}
+
+// refreshRatings Recalculate the aggregated ratings of a salon and stylist from visible reviews
+func refreshRatings(tx *gorm.DB, salonID, staffID uint) error {
+	err := tx.Model(&models.Salon{}).Where("id = ?", salonID).Updates(map[string]interface{}{
+		"average_rating": gorm.Expr("COALESCE((SELECT AVG(rating) FROM reviews WHERE salon_id = ? AND is_hidden = false AND deleted_at IS NULL), 0)", salonID),
+		"review_count":   gorm.Expr("(SELECT COUNT(*) FROM reviews WHERE salon_id = ? AND is_hidden = false AND deleted_at IS NULL)", salonID),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Staff{}).Where("id = ?", staffID).Updates(map[string]interface{}{
+		"average_rating": gorm.Expr("COALESCE((SELECT AVG(staff_rating) FROM reviews WHERE staff_id = ? AND staff_rating IS NOT NULL AND is_hidden = false AND deleted_at IS NULL), 0)", staffID),
+		"review_count":   gorm.Expr("(SELECT COUNT(*) FROM reviews WHERE staff_id = ? AND staff_rating IS NOT NULL AND is_hidden = false AND deleted_at IS NULL)", staffID),
+	}).Error
+}
+
+// isUniqueViolation Check whether a database error comes from a unique index
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
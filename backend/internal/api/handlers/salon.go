@@ -1,63 +1,182 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"reservation-platform-sample/internal/domain/models"
 	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/cache"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// SalonRequest Salon fields set by admins (ratings are aggregated from reviews)
+type SalonRequest struct {
+	Name                string                 `json:"name" binding:"required"`
+	NameKana            string                 `json:"name_kana"`
+	Description         string                 `json:"description"`
+	Address             string                 `json:"address" binding:"required"`
+	Phone               string                 `json:"phone"`
+	Email               string                 `json:"email"`
+	Website             string                 `json:"website"`
+	ImageURL            string                 `json:"image_url"`
+	OpeningHours        map[string]interface{} `json:"opening_hours"`
+	Latitude            float64                `json:"latitude"`
+	Longitude           float64                `json:"longitude"`
+	BufferBeforeMinutes int                    `json:"buffer_before_minutes"`
+	BufferAfterMinutes  int                    `json:"buffer_after_minutes"`
+	PaymentPolicy       string                 `json:"payment_policy"`
+	DepositPercent      int                    `json:"deposit_percent"`
+	FreeCancelHours     int                    `json:"free_cancel_hours"`
+	CancelRefundPercent int                    `json:"cancel_refund_percent"`
+}
+
 // GetSalons Get salon list
 func GetSalons(c *gin.Context) {
-	var salons []models.Salon
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Get query parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	search := c.Query("search")
+	filters, err := parseSalonFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	offset := (page - 1) * limit
+	// Summary without relations unless the client asks for more
+	view, err := parseSalonView(c, salonSummaryFields, nil)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if filters.AvailableOn != nil {
+		if filters.AvailableSalonIDs, err = availableSalonIDs(filters); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch salons"})
+			return
+		}
+	}
 
-	query := database.DB.Preload("Staff").Preload("Services")
+	fetch := func() (Page[models.Salon], error) {
+		var total int64
+		if err := applySalonFilters(database.DB.Model(&models.Salon{}), filters).Count(&total).Error; err != nil {
+			return Page[models.Salon]{}, err
+		}
 
-	// Search conditions
-	if search != "" {
-		query = query.Where("name ILIKE ? OR address ILIKE ?", "%"+search+"%", "%"+search+"%")
+		query := applySalonFilters(view.preload(database.DB.Model(&models.Salon{})), filters)
+		query = selectSalonColumns(query, filters, view.columns())
+
+		page, err := fetchPage(query, salonSortKeys(filters), "salons.id", req, func(salon models.Salon) uint { return salon.ID })
+		page.Total = &total
+		return page, err
 	}
 
-	if err := query.Offset(offset).Limit(limit).Find(&salons).Error; err != nil {
+	// Pages depending on the time of day or on bookings are always read fresh.
+	// Favorites and highlights are per request and added after the cache.
+	var page Page[models.Salon]
+	if filters.OpenNow || filters.AvailableOn != nil {
+		page, err = fetch()
+	} else {
+		page, err = cache.Remember(c.Request.Context(), responseCache, cacheKindSalonList, salonListNamespace, c.Request.URL.Query().Encode(), salonCacheTTL, fetch)
+	}
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch salons"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  salons,
-		"page":  page,
-		"limit": limit,
-	})
+	if err := markFavoriteSalons(c, page.Data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favorites"})
+		return
+	}
+
+	if filters.TextQuery != "" {
+		highlightSalons(page.Data, filters.Search)
+	}
+
+	data := make([]interface{}, len(page.Data))
+	for i, salon := range page.Data {
+		if data[i], err = view.render(salon); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch salons"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, Page[interface{}]{Data: data, NextCursor: page.NextCursor, HasMore: page.HasMore, Total: page.Total})
 }
 
 // GetSalon Get salon details
 func GetSalon(c *gin.Context) {
-	id := c.Param("id")
-	var salon models.Salon
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Salon not found"})
+		return
+	}
 
-	if err := database.DB.Preload("Staff").Preload("Services").First(&salon, id).Error; err != nil {
+	// Full salon with staff and services unless the client asks otherwise
+	view, err := parseSalonView(c, nil, []string{"staff", "services"})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	salon, err := cache.Remember(c.Request.Context(), responseCache, cacheKindSalon, salonNamespace(uint(id)), c.Request.URL.Query().Encode(), salonCacheTTL, func() (models.Salon, error) {
+		var salon models.Salon
+		query := view.preload(database.DB)
+		if columns := view.columns(); columns != nil {
+			query = query.Select(columns)
+		}
+		err := query.First(&salon, id).Error
+		return salon, err
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Salon not found"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch salon"})
+		return
+	}
 
-	c.JSON(http.StatusOK, salon)
+	salons := []models.Salon{salon}
+	if err := markFavoriteSalons(c, salons); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favorites"})
+		return
+	}
+
+	payload, err := view.render(salons[0])
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch salon"})
+		return
+	}
+
+	c.JSON(http.StatusOK, payload)
 }
 
-// CreateSalon Create salon
+// CreateSalon Create salon (platform admins)
 func CreateSalon(c *gin.Context) {
+	if !requirePlatformAdmin(c) {
+		return
+	}
+
+	var req SalonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var salon models.Salon
+	req.apply(&salon)
 
-	if err := c.ShouldBindJSON(&salon); err != nil {
+	if err := salonPaymentPolicy(salon).Validate(); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -67,20 +186,33 @@ func CreateSalon(c *gin.Context) {
 		return
 	}
 
+	refreshSearchIndex(salon.ID)
+	invalidateSalon(salon.ID)
+
 	c.JSON(http.StatusCreated, salon)
 }
 
-// UpdateSalon Update salon
+// UpdateSalon Update salon (fields left out of the request keep their value)
 func UpdateSalon(c *gin.Context) {
-	id := c.Param("id")
-	var salon models.Salon
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
 
-	if err := database.DB.First(&salon, id).Error; err != nil {
+	var salon models.Salon
+	if err := database.DB.First(&salon, salonID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Salon not found"})
 		return
 	}
 
-	if err := c.ShouldBindJSON(&salon); err != nil {
+	req := salonRequest(salon)
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.apply(&salon)
+
+	if err := salonPaymentPolicy(salon).Validate(); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -90,11 +222,18 @@ func UpdateSalon(c *gin.Context) {
 		return
 	}
 
+	refreshSearchIndex(salon.ID)
+	invalidateSalon(salon.ID)
+
 	c.JSON(http.StatusOK, salon)
 }
 
-// DeleteSalon Delete salon
+// DeleteSalon Delete salon (platform admins)
 func DeleteSalon(c *gin.Context) {
+	if !requirePlatformAdmin(c) {
+		return
+	}
+
 	id := c.Param("id")
 	var salon models.Salon
 
@@ -108,5 +247,51 @@ func DeleteSalon(c *gin.Context) {
 		return
 	}
 
+	invalidateSalon(salon.ID)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Salon deleted successfully"})
 }
+
+// salonRequest Request holding the salon's current values
+func salonRequest(salon models.Salon) SalonRequest {
+	return SalonRequest{
+		Name:                salon.Name,
+		NameKana:            salon.NameKana,
+		Description:         salon.Description,
+		Address:             salon.Address,
+		Phone:               salon.Phone,
+		Email:               salon.Email,
+		Website:             salon.Website,
+		ImageURL:            salon.ImageURL,
+		OpeningHours:        salon.OpeningHours,
+		Latitude:            salon.Latitude,
+		Longitude:           salon.Longitude,
+		BufferBeforeMinutes: salon.BufferBeforeMinutes,
+		BufferAfterMinutes:  salon.BufferAfterMinutes,
+		PaymentPolicy:       salon.PaymentPolicy,
+		DepositPercent:      salon.DepositPercent,
+		FreeCancelHours:     salon.FreeCancelHours,
+		CancelRefundPercent: salon.CancelRefundPercent,
+	}
+}
+
+// apply Copy the request onto the salon
+func (r SalonRequest) apply(salon *models.Salon) {
+	salon.Name = r.Name
+	salon.NameKana = r.NameKana
+	salon.Description = r.Description
+	salon.Address = r.Address
+	salon.Phone = r.Phone
+	salon.Email = r.Email
+	salon.Website = r.Website
+	salon.ImageURL = r.ImageURL
+	salon.OpeningHours = r.OpeningHours
+	salon.Latitude = r.Latitude
+	salon.Longitude = r.Longitude
+	salon.BufferBeforeMinutes = r.BufferBeforeMinutes
+	salon.BufferAfterMinutes = r.BufferAfterMinutes
+	salon.PaymentPolicy = r.PaymentPolicy
+	salon.DepositPercent = r.DepositPercent
+	salon.FreeCancelHours = r.FreeCancelHours
+	salon.CancelRefundPercent = r.CancelRefundPercent
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"reservation-platform-sample/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Relations that can be requested with include= (name in the API -> GORM association)
+var salonIncludes = map[string]string{
+	"staff":    "Staff",
+	"services": "Services",
+}
+
+// Fields of the lightweight salon summary returned by the list by default
+var salonSummaryFields = []string{
+	"id", "name", "address", "image_url", "latitude", "longitude",
+	"average_rating", "review_count", "distance_km", "is_favorite", "search_rank", "highlights",
+}
+
+// Fields that are computed per request rather than read from a salons column
+var salonComputedFields = map[string]bool{
+	"distance_km": true,
+	"search_rank": true,
+	"is_favorite": true,
+	"highlights":  true,
+}
+
+// salonFields JSON field names of a salon (relations excluded)
+var salonFields = jsonFieldNames(models.Salon{})
+
+// salonView Relations and fields of the salon payload requested by the client
+type salonView struct {
+	Includes []string        // API names of the relations to load
+	Fields   map[string]bool // nil means every field
+}
+
+// parseSalonView Parse the include and fields query parameters.
+// include=staff,services loads relations, fields=id,name,... limits the salon fields and fields=*
+// returns them all. The defaults apply when a parameter is absent.
+func parseSalonView(c *gin.Context, defaultFields []string, defaultIncludes []string) (salonView, error) {
+	view := salonView{Includes: defaultIncludes}
+
+	if value, ok := c.GetQuery("include"); ok {
+		view.Includes = nil
+		for _, name := range splitList(value) {
+			if _, ok := salonIncludes[name]; !ok {
+				return view, errors.New("invalid include: " + name)
+			}
+			view.Includes = append(view.Includes, name)
+		}
+	}
+
+	fields := defaultFields
+	if value := c.Query("fields"); value != "" {
+		fields = splitList(value)
+		if len(fields) == 1 && fields[0] == "*" {
+			fields = nil
+		}
+		for _, name := range fields {
+			if !salonFields[name] {
+				return view, errors.New("invalid field: " + name)
+			}
+		}
+	}
+
+	if fields != nil {
+		view.Fields = map[string]bool{"id": true}
+		for _, name := range fields {
+			view.Fields[name] = true
+		}
+	}
+
+	return view, nil
+}
+
+// preload Load the requested relations
+func (v salonView) preload(query *gorm.DB) *gorm.DB {
+	for _, name := range v.Includes {
+		query = query.Preload(salonIncludes[name])
+	}
+	return query
+}
+
+// columns Salon columns to read, or nil for every column.
+// Highlights are built from the name, description and address, so those are read when requested.
+func (v salonView) columns() []string {
+	if v.Fields == nil {
+		return nil
+	}
+
+	read := make(map[string]bool)
+	for name := range v.Fields {
+		if !salonComputedFields[name] {
+			read[name] = true
+		}
+	}
+	if v.Fields["highlights"] {
+		read["name"], read["description"], read["address"] = true, true, true
+	}
+
+	columns := make([]string, 0, len(read))
+	for name := range read {
+		columns = append(columns, "salons."+name)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// render Build the response payload of a salon: the requested fields plus the included relations
+func (v salonView) render(salon models.Salon) (interface{}, error) {
+	if v.Fields == nil {
+		return salon, nil
+	}
+
+	raw, err := json.Marshal(salon)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+
+	included := make(map[string]bool, len(v.Includes))
+	for _, name := range v.Includes {
+		included[name] = true
+	}
+	for name := range payload {
+		if !v.Fields[name] && !included[name] {
+			delete(payload, name)
+		}
+	}
+	return payload, nil
+}
+
+// splitList Split a comma separated query parameter, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// jsonFieldNames JSON names of the non-relation fields of a struct
+func jsonFieldNames(v interface{}) map[string]bool {
+	names := make(map[string]bool)
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
+			continue
+		}
+		names[name] = true
+	}
+	return names
+}
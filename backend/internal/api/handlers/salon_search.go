@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// minServicePriceSQL Lowest active service price of a salon
+const minServicePriceSQL = "(SELECT MIN(sv.price) FROM services sv WHERE sv.salon_id = salons.id AND sv.is_active AND sv.deleted_at IS NULL)"
+
+// searchRankSQL Full-text rank of a salon (0 when it has no search document)
+const searchRankSQL = "COALESCE(ts_rank(sd.document, ?::tsquery), 0)"
+
+// Sort orders accepted by the salon list (salons without services sort last by price)
+var salonSortOrders = map[string]func(filters salonFilters) []sortKey{
+	"relevance": func(filters salonFilters) []sortKey {
+		return []sortKey{{Expr: searchRankSQL, Args: []interface{}{filters.TextQuery}, Desc: true}}
+	},
+	"recommended": func(salonFilters) []sortKey {
+		return []sortKey{{Expr: "salons.average_rating * LN(salons.review_count + 1)", Desc: true}}
+	},
+	"rating": func(salonFilters) []sortKey {
+		return []sortKey{{Expr: "salons.average_rating", Desc: true}, {Expr: "salons.review_count", Desc: true}}
+	},
+	"reviews": func(salonFilters) []sortKey {
+		return []sortKey{{Expr: "salons.review_count", Desc: true}}
+	},
+	"distance": func(filters salonFilters) []sortKey {
+		return []sortKey{{Expr: distanceSQLPattern, Args: []interface{}{filters.Geo.Lat, filters.Geo.Lat, filters.Geo.Lng}}}
+	},
+	"price": func(salonFilters) []sortKey {
+		return []sortKey{{Expr: "COALESCE(" + minServicePriceSQL + ", 2147483647)"}}
+	},
+	"-price": func(salonFilters) []sortKey {
+		return []sortKey{{Expr: "COALESCE(" + minServicePriceSQL + ", -1)", Desc: true}}
+	},
+	"newest": func(salonFilters) []sortKey {
+		return []sortKey{{Expr: "salons.created_at", Desc: true}}
+	},
+	"name": func(salonFilters) []sortKey {
+		return []sortKey{{Expr: "salons.name"}}
+	},
+}
+
+var weekdayKeys = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// salonFilters Search conditions of the salon list
+type salonFilters struct {
+	Search      string
+	TextQuery   string // tsquery literal built from Search
+	Geo         geoQuery
+	Category    string
+	MinPrice    *int
+	MaxPrice    *int
+	MinRating   *float64
+	Specialty   string
+	OpenNow     bool
+	AvailableOn *time.Time
+	Sort        string
+
+	// Salons with a bookable slot on AvailableOn (resolved by availableSalonIDs; nil until then)
+	AvailableSalonIDs []uint
+}
+
+// parseSalonFilters Parse the salon list query parameters
+func parseSalonFilters(c *gin.Context) (salonFilters, error) {
+	filters := salonFilters{
+		Search:    strings.TrimSpace(c.Query("search")),
+		Category:  c.Query("category"),
+		Specialty: c.Query("specialty"),
+		OpenNow:   c.Query("open_now") == "true",
+	}
+
+	filters.TextQuery = search.Query(filters.Search)
+
+	var err error
+	if filters.Geo, err = parseGeoQuery(c); err != nil {
+		return filters, err
+	}
+
+	if value := c.Query("min_price"); value != "" {
+		price, err := strconv.Atoi(value)
+		if err != nil || price < 0 {
+			return filters, errors.New("invalid min_price")
+		}
+		filters.MinPrice = &price
+	}
+	if value := c.Query("max_price"); value != "" {
+		price, err := strconv.Atoi(value)
+		if err != nil || price < 0 {
+			return filters, errors.New("invalid max_price")
+		}
+		filters.MaxPrice = &price
+	}
+	if value := c.Query("min_rating"); value != "" {
+		rating, err := strconv.ParseFloat(value, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			return filters, errors.New("invalid min_rating")
+		}
+		filters.MinRating = &rating
+	}
+	if value := c.Query("available_on"); value != "" {
+		date, err := time.ParseInLocation("2006-01-02", value, salonLocation)
+		if err != nil {
+			return filters, errors.New("invalid available_on")
+		}
+		filters.AvailableOn = &date
+	}
+
+	// Best match first by default for keyword searches, nearest first when searching around a point
+	filters.Sort = c.Query("sort")
+	if filters.Sort == "" {
+		switch {
+		case filters.TextQuery != "":
+			filters.Sort = "relevance"
+		case filters.Geo.Center:
+			filters.Sort = "distance"
+		default:
+			filters.Sort = "recommended"
+		}
+	}
+	if _, ok := salonSortOrders[filters.Sort]; !ok {
+		return filters, errors.New("invalid sort")
+	}
+	if filters.Sort == "distance" && !filters.Geo.Center {
+		return filters, errors.New("sort=distance requires lat and lng")
+	}
+	if filters.Sort == "relevance" && filters.TextQuery == "" {
+		return filters, errors.New("sort=relevance requires search")
+	}
+
+	return filters, nil
+}
+
+// applySalonFilters Add the search conditions to a salon query
+func applySalonFilters(query *gorm.DB, filters salonFilters) *gorm.DB {
+	// Full-text search over salon, service and staff text. Salons not indexed yet
+	// (the indexer catches up in the background) match their own text by substring.
+	if filters.TextQuery != "" {
+		pattern := likePattern(filters.Search)
+		query = query.
+			Joins("LEFT JOIN search_documents sd ON sd.salon_id = salons.id").
+			Where(`(sd.document @@ ?::tsquery OR (sd.salon_id IS NULL AND (
+				salons.name ILIKE ? OR salons.name_kana ILIKE ? OR salons.address ILIKE ? OR salons.description ILIKE ?
+			)))`, filters.TextQuery, pattern, pattern, pattern, pattern)
+	}
+
+	query = applyGeoQuery(query, filters.Geo)
+
+	if filters.MinRating != nil {
+		query = query.Where("salons.average_rating >= ?", *filters.MinRating)
+	}
+
+	// Category and price range must match the same service
+	if filters.Category != "" || filters.MinPrice != nil || filters.MaxPrice != nil {
+		conditions := []string{"sv.salon_id = salons.id", "sv.is_active", "sv.deleted_at IS NULL"}
+		var args []interface{}
+		if filters.Category != "" {
+			conditions = append(conditions, "sv.category = ?")
+			args = append(args, filters.Category)
+		}
+		if filters.MinPrice != nil {
+			conditions = append(conditions, "sv.price >= ?")
+			args = append(args, *filters.MinPrice)
+		}
+		if filters.MaxPrice != nil {
+			conditions = append(conditions, "sv.price <= ?")
+			args = append(args, *filters.MaxPrice)
+		}
+		query = query.Where("EXISTS (SELECT 1 FROM services sv WHERE "+strings.Join(conditions, " AND ")+")", args...)
+	}
+
+	if filters.Specialty != "" {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM staffs st WHERE st.salon_id = salons.id AND st.is_active AND st.deleted_at IS NULL AND ? = ANY(st.specialties))",
+			filters.Specialty,
+		)
+	}
+
+	// Opening hours are stored as {"monday": {"open": "09:00", "close": "19:00"}, ...}.
+	// A closing time not after the opening time runs past midnight into the next day.
+	if filters.OpenNow {
+		now := time.Now().In(salonLocation)
+		today := weekdayKeys[now.Weekday()]
+		yesterday := weekdayKeys[(now.Weekday()+6)%7]
+		clock := now.Format("15:04")
+		query = query.Where(`(
+			(salons.opening_hours -> @today ->> 'open' <= @clock AND (
+				salons.opening_hours -> @today ->> 'close' > @clock
+				OR salons.opening_hours -> @today ->> 'close' <= salons.opening_hours -> @today ->> 'open'
+			))
+			OR (
+				salons.opening_hours -> @yesterday ->> 'close' <= salons.opening_hours -> @yesterday ->> 'open'
+				AND salons.opening_hours -> @yesterday ->> 'close' > @clock
+			)
+		)`, sql.Named("today", today), sql.Named("yesterday", yesterday), sql.Named("clock", clock))
+	}
+
+	// Capacity check narrowing the candidates: some active staff member is not absent all day and
+	// still has bookable time left. availableSalonIDs confirms each candidate with the slot search.
+	if filters.AvailableOn != nil {
+		day := dayRange(*filters.AvailableOn)
+		windowStart := day.Start.Add(slotDayStartHour * time.Hour)
+		windowEnd := day.Start.Add(slotDayEndHour * time.Hour)
+		capacityMinutes := (slotDayEndHour-slotDayStartHour)*60 - defaultSlotMinutes
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM staffs st
+			WHERE st.salon_id = salons.id AND st.is_active AND st.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM staff_blocks b
+				WHERE b.staff_id = st.id AND b.deleted_at IS NULL AND b.recurrence = ''
+				AND b.start_time <= ? AND b.end_time >= ?
+			)
+			AND COALESCE((
+				SELECT SUM(EXTRACT(EPOCH FROM LEAST(r.end_time, ?) - GREATEST(r.start_time, ?)) / 60)
+				FROM reservations r
+				WHERE r.staff_id = st.id AND r.status != 'cancelled' AND r.deleted_at IS NULL
+				AND r.start_time < ? AND r.end_time > ?
+			), 0) <= ?
+		)`, windowStart, windowEnd, windowEnd, windowStart, windowEnd, windowStart, capacityMinutes)
+
+		if filters.AvailableSalonIDs != nil {
+			query = query.Where("salons.id IN ?", filters.AvailableSalonIDs)
+		}
+	}
+
+	return query
+}
+
+// likePattern ILIKE pattern matching text anywhere, with the wildcard characters escaped
+func likePattern(text string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+	return "%" + escaped + "%"
+}
+
+// availableSalonIDs Salons matching the filters with a bookable slot on filters.AvailableOn.
+// Candidates passing the capacity check are confirmed with the same busy ranges
+// (bookings, holds, buffers and blocks) the slot endpoint uses.
+func availableSalonIDs(filters salonFilters) ([]uint, error) {
+	filters.AvailableSalonIDs = nil
+
+	var candidates []uint
+	if err := applySalonFilters(database.DB.Model(&models.Salon{}), filters).Pluck("salons.id", &candidates).Error; err != nil {
+		return nil, err
+	}
+
+	ids := []uint{}
+	for _, id := range candidates {
+		options, _, err := findSlotOptions(strconv.Itoa(int(id)), "", "", *filters.AvailableOn)
+		if err != nil {
+			return nil, err
+		}
+		if len(options) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// selectSalonColumns Select the salon columns (every column when nil) plus the computed distance and search rank
+func selectSalonColumns(query *gorm.DB, filters salonFilters, salonColumns []string) *gorm.DB {
+	columns := []string{"salons.*"}
+	if salonColumns != nil {
+		columns = salonColumns
+	}
+	var args []interface{}
+
+	if filters.Geo.Center {
+		columns = append(columns, distanceSQLPattern+" AS distance_km")
+		args = append(args, filters.Geo.Lat, filters.Geo.Lat, filters.Geo.Lng)
+	}
+	if filters.TextQuery != "" {
+		columns = append(columns, searchRankSQL+" AS search_rank")
+		args = append(args, filters.TextQuery)
+	}
+
+	return query.Select(strings.Join(columns, ", "), args...)
+}
+
+// salonSortKeys Sort keys of the requested salon order
+func salonSortKeys(filters salonFilters) []sortKey {
+	return salonSortOrders[filters.Sort](filters)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReindexSearch Rebuild the search index of every salon
+func ReindexSearch(c *gin.Context) {
+	if !requirePlatformAdmin(c) {
+		return
+	}
+
+	var salonIDs []uint
+	if err := database.DB.Model(&models.Salon{}).Pluck("id", &salonIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch salons"})
+		return
+	}
+
+	for _, salonID := range salonIDs {
+		if err := reindexSalon(database.DB, salonID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to index salon"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"indexed": len(salonIDs)})
+}
+
+// staleSearchSalonsSQL Salons without a search document, or whose salon, staff or service rows
+// changed (soft deletes included) after the document was built
+const staleSearchSalonsSQL = `
+SELECT s.id FROM salons s
+LEFT JOIN search_documents sd ON sd.salon_id = s.id
+WHERE s.deleted_at IS NULL AND (
+	sd.salon_id IS NULL
+	OR s.updated_at > sd.updated_at
+	OR EXISTS (SELECT 1 FROM staffs st WHERE st.salon_id = s.id AND GREATEST(st.updated_at, st.deleted_at) > sd.updated_at)
+	OR EXISTS (SELECT 1 FROM services sv WHERE sv.salon_id = s.id AND GREATEST(sv.updated_at, sv.deleted_at) > sd.updated_at)
+)
+ORDER BY s.id`
+
+// ReindexStaleSalons Index the salons whose search document is missing or out of date.
+// Run after migrations to backfill salons created before the index or edited in the database directly.
+func ReindexStaleSalons() (int, error) {
+	var salonIDs []uint
+	if err := database.DB.Raw(staleSearchSalonsSQL).Scan(&salonIDs).Error; err != nil {
+		return 0, err
+	}
+
+	for _, salonID := range salonIDs {
+		if err := reindexSalon(database.DB, salonID); err != nil {
+			return 0, fmt.Errorf("salon %d: %w", salonID, err)
+		}
+	}
+	return len(salonIDs), nil
+}
+
+// RunSearchIndexer Periodically reindex salons whose staff or services changed
+func RunSearchIndexer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := ReindexStaleSalons(); err != nil {
+			log.Printf("Failed to update search index: %v", err)
+		}
+	}
+}
+
+// refreshSearchIndex Refresh a salon's search index entry (failures are logged, not returned)
+func refreshSearchIndex(salonID uint) {
+	if err := reindexSalon(database.DB, salonID); err != nil {
+		log.Printf("Failed to index salon %d: %v", salonID, err)
+	}
+}
+
+// reindexSalon Build the search index entry of a salon from its own, service and staff text
+func reindexSalon(db *gorm.DB, salonID uint) error {
+	// Changes saved while the document is built stay newer than it and are picked up next time
+	builtAt := time.Now()
+
+	var salon models.Salon
+	err := db.
+		Preload("Staff", "is_active = ?", true).
+		Preload("Services", "is_active = ?", true).
+		First(&salon, salonID).Error
+	if err != nil {
+		return err
+	}
+
+	fields := []search.Field{
+		{Text: salon.Name, Weight: search.WeightA},
+		{Text: salon.NameKana, Weight: search.WeightA},
+		{Text: salon.Address, Weight: search.WeightC},
+		{Text: salon.Description, Weight: search.WeightC},
+	}
+	for _, service := range salon.Services {
+		fields = append(fields,
+			search.Field{Text: service.Name, Weight: search.WeightB},
+			search.Field{Text: service.Category, Weight: search.WeightB},
+			search.Field{Text: service.Description, Weight: search.WeightD},
+		)
+	}
+	for _, staff := range salon.Staff {
+		fields = append(fields,
+			search.Field{Text: staff.Name, Weight: search.WeightB},
+			search.Field{Text: strings.Join(staff.Specialties, " "), Weight: search.WeightB},
+			search.Field{Text: staff.Description, Weight: search.WeightD},
+		)
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "salon_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"document", "updated_at"}),
+	}).Create(&models.SearchDocument{
+		SalonID:   salon.ID,
+		Document:  search.Vector(fields...),
+		UpdatedAt: builtAt,
+	}).Error
+}
+
+// highlightSalons Attach search snippets to the salons
+func highlightSalons(salons []models.Salon, query string) {
+	for i := range salons {
+		highlights := make(map[string]string)
+		for field, text := range map[string]string{
+			"name":        salons[i].Name,
+			"description": salons[i].Description,
+			"address":     salons[i].Address,
+		} {
+			if snippet := search.Highlight(text, query); snippet != "" {
+				highlights[field] = snippet
+			}
+		}
+
+		// First matching service and staff member (when loaded)
+		for _, service := range salons[i].Services {
+			if snippet := search.Highlight(service.Name, query); snippet != "" {
+				highlights["service"] = snippet
+				break
+			}
+		}
+		for _, staff := range salons[i].Staff {
+			if snippet := search.Highlight(staff.Name, query); snippet != "" {
+				highlights["staff"] = snippet
+				break
+			}
+		}
+
+		if len(highlights) > 0 {
+			salons[i].Highlights = highlights
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/realtime"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// slotHoldDuration How long a slot stays held for the customer filling in the booking form
+const slotHoldDuration = 10 * time.Minute
+
+// maxActiveHoldsPerUser Unexpired holds a customer may have at once (one booking form, plus a change of mind)
+const maxActiveHoldsPerUser = 2
+
+var errHoldLimit = errors.New("too many active holds; release one or wait for it to expire")
+
+type SlotHoldRequest struct {
+	StaffID   uint      `json:"staff_id" binding:"required"`
+	ServiceID uint      `json:"service_id" binding:"required"`
+	StartTime time.Time `json:"start_time" binding:"required"`
+}
+
+// CreateSlotHold Hold a slot while the customer completes the booking (send hold_token with the booking)
+func CreateSlotHold(c *gin.Context) {
+	salonID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid salon ID"})
+		return
+	}
+
+	var req SlotHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	candidate := models.Reservation{
+		SalonID:   uint(salonID),
+		StaffID:   req.StaffID,
+		ServiceID: req.ServiceID,
+		StartTime: req.StartTime,
+	}
+	if err := prepareReservation(&candidate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := newHoldToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hold slot"})
+		return
+	}
+
+	userID := c.GetUint("userID")
+	now := time.Now()
+	hold := models.SlotHold{
+		Token:               token,
+		UserID:              userID,
+		SalonID:             candidate.SalonID,
+		StaffID:             candidate.StaffID,
+		ServiceID:           candidate.ServiceID,
+		StartTime:           candidate.StartTime,
+		EndTime:             candidate.EndTime,
+		BufferBeforeMinutes: candidate.BufferBeforeMinutes,
+		BufferAfterMinutes:  candidate.BufferAfterMinutes,
+		ExpiresAt:           now.Add(slotHoldDuration),
+	}
+
+	var ruleErr error
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Holds of the same customer and of the same staff member are created one at a time,
+		// so the limit and the availability check below see every hold committed before
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Staff{}, candidate.StaffID).Error; err != nil {
+			return err
+		}
+
+		// Expired holds no longer block anything
+		if err := tx.Where("expires_at <= ?", now).Delete(&models.SlotHold{}).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		if err := tx.Model(&models.SlotHold{}).Where("user_id = ? AND expires_at > ?", userID, now).Count(&active).Error; err != nil {
+			return err
+		}
+		if active >= maxActiveHoldsPerUser {
+			return errHoldLimit
+		}
+
+		// The slot must be bookable now, including against other holds
+		if ruleErr = checkBookingRules(&candidate); ruleErr != nil {
+			return ruleErr
+		}
+
+		if err := tx.Create(&hold).Error; err != nil {
+			return err
+		}
+		return publishChange(tx, holdChange(realtime.ChangeHoldCreated, hold))
+	})
+	if errors.Is(err, errHoldLimit) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if ruleErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ruleErr.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hold slot"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// ReleaseSlotHold Give one of the customer's held slots back before the hold expires
+func ReleaseSlotHold(c *gin.Context) {
+	var hold models.SlotHold
+	if err := database.DB.Where("token = ? AND user_id = ?", c.Param("token"), c.GetUint("userID")).First(&hold).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		return
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&hold).Error; err != nil {
+			return err
+		}
+		return publishChange(tx, holdChange(realtime.ChangeHoldReleased, hold))
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release hold"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hold released successfully"})
+}
+
+// holdChange Live change for a slot hold
+func holdChange(changeType string, hold models.SlotHold) realtime.Change {
+	return realtime.Change{
+		Type:    changeType,
+		SalonID: hold.SalonID,
+		StaffID: hold.StaffID,
+		Date:    hold.StartTime.In(salonLocation).Format("2006-01-02"),
+		HoldID:  hold.ID,
+	}
+}
+
+// newHoldToken Random token identifying a slot hold
+func newHoldToken() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/webhook"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StaffInvitationRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// errStaffAccountLinked User account already linked to a staff member
+var errStaffAccountLinked = errors.New("user is already linked to a staff member")
+
+// InviteStaffUser Invite a user account to a staff member; it is linked once the user accepts
+func InviteStaffUser(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	var req StaffInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if staff.UserID != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Staff member is already linked to a user account"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, req.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Accounts working for another salon (or this one) can't be taken over
+	var linked int64
+	if err := database.DB.Model(&models.Staff{}).Where("user_id = ?", user.ID).Count(&linked).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite user"})
+		return
+	}
+	if linked > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already linked to a staff member"})
+		return
+	}
+
+	if err := database.DB.Model(staff).Update("invited_user_id", user.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// GetStaffInvitations Get the staff members the user is invited to link their account to
+func GetStaffInvitations(c *gin.Context) {
+	var invitations []models.Staff
+	if err := database.DB.Where("invited_user_id = ? AND user_id IS NULL", c.GetUint("userID")).
+		Preload("Salon").
+		Order("id").
+		Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptStaffInvitation Link the user's account to the staff member that invited it (customers become staff)
+func AcceptStaffInvitation(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	var staff models.Staff
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND invited_user_id = ? AND user_id IS NULL", c.Param("id"), userID).
+			First(&staff).Error; err != nil {
+			return err
+		}
+
+		var linked int64
+		if err := tx.Model(&models.Staff{}).Where("user_id = ?", userID).Count(&linked).Error; err != nil {
+			return err
+		}
+		if linked > 0 {
+			return errStaffAccountLinked
+		}
+
+		staff.UserID, staff.InvitedUserID = &userID, nil
+		if err := tx.Model(&staff).Select("user_id", "invited_user_id").Updates(&staff).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ? AND role = ?", userID, "customer").Update("role", "staff").Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+	if errors.Is(err, errStaffAccountLinked) || isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Your account is already linked to a staff member"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+	invalidateSalon(staff.SalonID)
+
+	c.JSON(http.StatusOK, staff)
+}
+
+// DeclineStaffInvitation Turn down an invitation to link the user's account to a staff member
+func DeclineStaffInvitation(c *gin.Context) {
+	result := database.DB.Model(&models.Staff{}).
+		Where("id = ? AND invited_user_id = ?", c.Param("id"), c.GetUint("userID")).
+		Update("invited_user_id", nil)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline invitation"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// GetMyAgenda Get the calling staff member's reservations for a day
+func GetMyAgenda(c *gin.Context) {
+	staff, ok := currentStaff(c)
+	if !ok {
+		return
+	}
+
+	date := time.Now().In(salonLocation)
+	if value := c.Query("date"); value != "" {
+		parsedDate, err := time.ParseInLocation("2006-01-02", value, salonLocation)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+		date = parsedDate
+	}
+
+	day := dayRange(date)
+	var reservations []models.Reservation
+	if err := database.DB.Where(
+		"staff_id = ? AND status != 'cancelled' AND start_time >= ? AND start_time < ?",
+		staff.ID,
+		day.Start,
+		day.End,
+	).
+		Preload("User").
+		Preload("Service").
+		Order("start_time").
+		Find(&reservations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reservations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"staff":        staff,
+		"date":         day.Start.Format("2006-01-02"),
+		"reservations": reservations,
+	})
+}
+
+// CheckInReservation Mark the customer as arrived
+func CheckInReservation(c *gin.Context) {
+	updateStaffReservationStatus(c, "checked_in", "confirmed")
+}
+
+// CompleteReservation Mark the reservation as completed
+func CompleteReservation(c *gin.Context) {
+	updateStaffReservationStatus(c, "completed", "confirmed", "checked_in")
+}
+
+// MarkNoShow Mark the customer as not having arrived
+func MarkNoShow(c *gin.Context) {
+	updateStaffReservationStatus(c, "no_show", "confirmed")
+}
+
+// updateStaffReservationStatus Move one of the calling staff member's reservations to a new status
+func updateStaffReservationStatus(c *gin.Context, status string, allowedFrom ...string) {
+	staff, ok := currentStaff(c)
+	if !ok {
+		return
+	}
+
+	var reservation models.Reservation
+	if err := database.DB.Where("id = ? AND staff_id = ?", c.Param("id"), staff.ID).First(&reservation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	allowed := false
+	for _, from := range allowedFrom {
+		if reservation.Status == from {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot change status from " + reservation.Status + " to " + status})
+		return
+	}
+
+	now := time.Now()
+	if status == "no_show" && now.Before(reservation.StartTime) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Reservation has not started yet"})
+		return
+	}
+
+	reservation.Status = status
+	switch status {
+	case "checked_in":
+		reservation.CheckedInAt = &now
+	case "completed":
+		reservation.CompletedAt = &now
+	}
+
+	// Completed reservations earn loyalty points for the customer
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&reservation).Error; err != nil {
+			return err
+		}
+		if status != "completed" {
+			return enqueueReservationEvent(tx, webhook.EventReservationUpdated, reservation)
+		}
+		if err := earnPoints(tx, reservation); err != nil {
+			return err
+		}
+		return enqueueReservationEvent(tx, webhook.EventReservationCompleted, reservation)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reservation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+// currentStaff Get the staff member linked to the authenticated user
+func currentStaff(c *gin.Context) (*models.Staff, bool) {
+	var staff models.Staff
+	if err := database.DB.Where("user_id = ?", c.GetUint("userID")).First(&staff).Error; err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No staff profile linked to this account"})
+		return nil, false
+	}
+
+	return &staff, true
+}
+
+// loadManagedStaff Load the staff member in the :id path parameter if the admin may manage their salon
+func loadManagedStaff(c *gin.Context) (*models.Staff, bool) {
+	var staff models.Staff
+	if err := database.DB.First(&staff, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
+		return nil, false
+	}
+	if !canManageSalon(c, staff.SalonID) {
+		return nil, false
+	}
+
+	return &staff, true
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StaffBlockRequest struct {
+	StartTime       time.Time  `json:"start_time" binding:"required"`
+	EndTime         time.Time  `json:"end_time"`
+	AllDay          bool       `json:"all_day"`
+	Reason          string     `json:"reason"`
+	Recurrence      string     `json:"recurrence" binding:"omitempty,oneof=daily weekly"`
+	RecurrenceUntil *time.Time `json:"recurrence_until"`
+}
+
+type RescheduleMove struct {
+	ReservationID uint       `json:"reservation_id" binding:"required"`
+	StaffID       uint       `json:"staff_id"`   // Keep the current staff member when omitted
+	StartTime     *time.Time `json:"start_time"` // Keep the current time when omitted
+}
+
+type RescheduleRequest struct {
+	Moves []RescheduleMove `json:"moves" binding:"required,min=1,dive"`
+}
+
+type RescheduleResult struct {
+	ReservationID uint                `json:"reservation_id"`
+	Reservation   *models.Reservation `json:"reservation,omitempty"`
+	Error         string              `json:"error,omitempty"`
+}
+
+// GetStaffBlocks Get staff blocks
+func GetStaffBlocks(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.StaffBlock{}).Where("staff_id = ?", staff.ID)
+	page, err := fetchPage(query, []sortKey{{Expr: "start_time"}}, "id", req, func(block models.StaffBlock) uint { return block.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staff blocks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreateStaffBlock Create staff block and report reservations colliding with it
+func CreateStaffBlock(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	var req StaffBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	block := models.StaffBlock{
+		StaffID:         staff.ID,
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		AllDay:          req.AllDay,
+		Reason:          req.Reason,
+		Recurrence:      req.Recurrence,
+		RecurrenceUntil: req.RecurrenceUntil,
+	}
+
+	// Full-day absences cover whole salon days (end date inclusive)
+	if block.AllDay {
+		block.StartTime = dayRange(req.StartTime.In(salonLocation)).Start
+		if req.EndTime.IsZero() {
+			block.EndTime = dayRange(block.StartTime).End
+		} else {
+			block.EndTime = dayRange(req.EndTime.In(salonLocation)).End
+		}
+	}
+
+	if !block.EndTime.After(block.StartTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "End time must be after start time"})
+		return
+	}
+
+	if err := database.DB.Create(&block).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create staff block"})
+		return
+	}
+	invalidateSlots(staff.SalonID)
+
+	conflicting, err := findBlockConflicts(block)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conflicting reservations"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"block":     block,
+		"conflicts": conflicting,
+	})
+}
+
+// DeleteStaffBlock Delete staff block
+func DeleteStaffBlock(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	var block models.StaffBlock
+	if err := database.DB.Where("id = ? AND staff_id = ?", c.Param("block_id"), staff.ID).First(&block).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff block not found"})
+		return
+	}
+
+	// Imported blocks come back with the next import; they are removed in the source calendar
+	if block.Source == staffBlockSourceICS {
+		c.JSON(http.StatusConflict, gin.H{"error": "Imported blocks are managed by the staff calendar source"})
+		return
+	}
+
+	if err := database.DB.Delete(&block).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete staff block"})
+		return
+	}
+	invalidateSlots(staff.SalonID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Staff block deleted successfully"})
+}
+
+// GetStaffBlockConflicts Get upcoming reservations colliding with a staff block
+func GetStaffBlockConflicts(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	var block models.StaffBlock
+	if err := database.DB.Where("id = ? AND staff_id = ?", c.Param("block_id"), staff.ID).First(&block).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff block not found"})
+		return
+	}
+
+	conflicting, err := findBlockConflicts(block)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conflicting reservations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conflicting)
+}
+
+// RescheduleStaffBlockConflicts Move reservations colliding with a staff block to another staff member or time
+func RescheduleStaffBlockConflicts(c *gin.Context) {
+	staff, ok := loadManagedStaff(c)
+	if !ok {
+		return
+	}
+
+	var block models.StaffBlock
+	if err := database.DB.Where("id = ? AND staff_id = ?", c.Param("block_id"), staff.ID).First(&block).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Staff block not found"})
+		return
+	}
+
+	var req RescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Each move is applied independently so one invalid move doesn't block the others
+	results := make([]RescheduleResult, 0, len(req.Moves))
+	for _, move := range req.Moves {
+		result := RescheduleResult{ReservationID: move.ReservationID}
+
+		var reservation models.Reservation
+		if err := database.DB.Where("id = ? AND staff_id = ? AND status = 'confirmed'", move.ReservationID, block.StaffID).First(&reservation).Error; err != nil {
+			result.Error = "Reservation not found"
+			results = append(results, result)
+			continue
+		}
+
+		// Moves go through the same checks, pricing and notifications as the customer's own changes
+		moved := reservation
+		if move.StaffID != 0 {
+			moved.StaffID = move.StaffID
+		}
+		if move.StartTime != nil {
+			moved.StartTime = *move.StartTime
+		}
+
+		if err := changeReservation(reservation, &moved); err != nil {
+			var rejected *bookingError
+			if errors.As(err, &rejected) {
+				result.Error = err.Error()
+			} else {
+				result.Error = "Failed to update reservation"
+			}
+			results = append(results, result)
+			continue
+		}
+
+		result.Reservation = &moved
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// findBlockConflicts Find upcoming confirmed reservations overlapping any occurrence of the block
+func findBlockConflicts(block models.StaffBlock) ([]models.Reservation, error) {
+	from := time.Now()
+	if block.StartTime.After(from) {
+		from = block.StartTime
+	}
+
+	query := database.DB.Where("staff_id = ? AND status = 'confirmed' AND end_time > ?", block.StaffID, from)
+	if block.Recurrence == "" {
+		query = query.Where("start_time < ?", block.EndTime)
+	} else if block.RecurrenceUntil != nil {
+		query = query.Where("start_time < ?", block.RecurrenceUntil.Add(block.EndTime.Sub(block.StartTime)))
+	}
+
+	var reservations []models.Reservation
+	if err := query.Preload("User").Preload("Service").Order("start_time").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+
+	conflicting := []models.Reservation{}
+	for _, reservation := range reservations {
+		span := timeRange{Start: reservation.StartTime, End: reservation.EndTime}
+		if conflicts(reservationBusyRanges(reservation), blockOccurrences(block, span)) {
+			conflicting = append(conflicting, reservation)
+		}
+	}
+
+	return conflicting, nil
+}
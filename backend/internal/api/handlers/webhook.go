@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+	"reservation-platform-sample/internal/services/safehttp"
+	"reservation-platform-sample/internal/services/webhook"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Dispatcher batch sizes, request timeout and how long a claimed delivery is hidden from other instances.
+// The lease outlasts a whole batch of timed-out requests so no other instance sends them again.
+const (
+	outboxBatchSize   = 100
+	deliveryBatchSize = 10
+	deliveryTimeout   = 10 * time.Second
+	deliveryLease     = deliveryBatchSize*deliveryTimeout + time.Minute
+)
+
+// webhookClient Client delivering webhook requests
+var webhookClient = webhook.NewClient(deliveryTimeout)
+
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`    // Empty for every event
+	IsActive    *bool    `json:"is_active"` // Update only; new endpoints are active
+}
+
+// WebhookEndpointResponse Endpoint with its signing secret (only returned on creation)
+type WebhookEndpointResponse struct {
+	models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// GetWebhookEndpoints Get a salon's webhook endpoints
+func GetWebhookEndpoints(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.WebhookEndpoint{}).Where("salon_id = ?", salonID)
+	page, err := fetchPage(query, nil, "id", req, func(endpoint models.WebhookEndpoint) uint { return endpoint.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook endpoints"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreateWebhookEndpoint Register a webhook endpoint and generate its signing secret
+func CreateWebhookEndpoint(c *gin.Context) {
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	endpoint := models.WebhookEndpoint{
+		SalonID:     salonID,
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Secret:      secret,
+		IsActive:    true,
+	}
+
+	if err := validateWebhookEndpoint(endpoint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&endpoint).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook endpoint"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookEndpointResponse{WebhookEndpoint: endpoint, Secret: secret})
+}
+
+// UpdateWebhookEndpoint Update a webhook endpoint (the secret is kept)
+func UpdateWebhookEndpoint(c *gin.Context) {
+	endpoint, ok := findWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	endpoint.URL = req.URL
+	endpoint.Description = req.Description
+	endpoint.Events = req.Events
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if err := validateWebhookEndpoint(endpoint); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Save(&endpoint).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook endpoint"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// DeleteWebhookEndpoint Delete a webhook endpoint (pending deliveries are dropped)
+func DeleteWebhookEndpoint(c *gin.Context) {
+	endpoint, ok := findWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Delete(&endpoint).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook endpoint"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook endpoint deleted successfully"})
+}
+
+// GetWebhookDeliveries Get the delivery log of an endpoint (latest first, status= to filter)
+func GetWebhookDeliveries(c *gin.Context) {
+	endpoint, ok := findWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	req, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := database.DB.Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpoint.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	page, err := fetchPage(query, []sortKey{{Expr: "created_at", Desc: true}}, "id", req, func(delivery models.WebhookDelivery) uint { return delivery.ID })
+	if errors.Is(err, errInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhook deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetWebhookDelivery Get a delivery with its event and every attempt
+func GetWebhookDelivery(c *gin.Context) {
+	endpoint, ok := findWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := database.DB.Where("id = ? AND endpoint_id = ?", c.Param("delivery_id"), endpoint.ID).
+		Preload("Event").
+		Preload("AttemptLog", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&delivery).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// RedeliverWebhook Queue a delivery again with a fresh retry budget
+func RedeliverWebhook(c *gin.Context) {
+	endpoint, ok := findWebhookEndpoint(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	result := database.DB.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND endpoint_id = ?", c.Param("delivery_id"), endpoint.ID).
+		Updates(map[string]interface{}{
+			"status":          webhook.StatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue delivery"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Delivery queued"})
+}
+
+// validateWebhookEndpoint Check an endpoint's URL (public http or https only) and event types
+func validateWebhookEndpoint(endpoint models.WebhookEndpoint) error {
+	if err := safehttp.CheckURL(endpoint.URL); err != nil {
+		return err
+	}
+	for _, event := range endpoint.Events {
+		if !webhook.ValidEvent(event) {
+			return errors.New("unknown event type: " + event)
+		}
+	}
+	return nil
+}
+
+// findWebhookEndpoint Load the salon's endpoint in the URL. Writes the error response on failure.
+func findWebhookEndpoint(c *gin.Context) (models.WebhookEndpoint, bool) {
+	var endpoint models.WebhookEndpoint
+
+	salonID, ok := authorizeSalonAdmin(c)
+	if !ok {
+		return endpoint, false
+	}
+
+	if err := database.DB.Where("id = ? AND salon_id = ?", c.Param("webhook_id"), salonID).First(&endpoint).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook endpoint not found"})
+		return endpoint, false
+	}
+	return endpoint, true
+}
+
+// enqueueReservationEvent Record a reservation event in the outbox and notify live subscribers.
+// Must run in the transaction making the change.
+func enqueueReservationEvent(tx *gorm.DB, eventType string, reservation models.Reservation) error {
+	if err := publishChange(tx, reservationChange(eventType, reservation)); err != nil {
+		return err
+	}
+
+	// Associations are left out so the payload only describes the reservation itself
+	reservation.Salon, reservation.Staff, reservation.User, reservation.Service = nil, nil, nil, nil
+	reservation.Payments = nil
+
+	payload, err := json.Marshal(reservation)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxEvent{
+		SalonID: reservation.SalonID,
+		Type:    eventType,
+		Payload: string(payload),
+	}).Error
+}
+
+// RunWebhookDispatcher Fan out outbox events and deliver due webhooks every interval until the context ends.
+// Several server instances can run it at once; rows are claimed with SKIP LOCKED.
+func RunWebhookDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := dispatchOutbox(time.Now()); err != nil {
+			log.Printf("Failed to dispatch webhook events: %v", err)
+		}
+		if err := deliverDueWebhooks(ctx, time.Now()); err != nil {
+			log.Printf("Failed to deliver webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchOutbox Create a delivery per subscribed endpoint for events not dispatched yet
+func dispatchOutbox(now time.Time) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at IS NULL").Order("id").Limit(outboxBatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		endpointsBySalon := make(map[uint][]models.WebhookEndpoint)
+		for _, event := range events {
+			endpoints, ok := endpointsBySalon[event.SalonID]
+			if !ok {
+				if err := tx.Where("salon_id = ? AND is_active = ?", event.SalonID, true).Find(&endpoints).Error; err != nil {
+					return err
+				}
+				endpointsBySalon[event.SalonID] = endpoints
+			}
+
+			for _, endpoint := range endpoints {
+				if !endpoint.Subscribed(event.Type) {
+					continue
+				}
+				delivery := models.WebhookDelivery{
+					EndpointID:    endpoint.ID,
+					EventID:       event.ID,
+					EventType:     event.Type,
+					Status:        webhook.StatusPending,
+					NextAttemptAt: &now,
+				}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&delivery).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Model(&event).Update("dispatched_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deliverDueWebhooks Send the deliveries whose next attempt is due
+func deliverDueWebhooks(ctx context.Context, now time.Time) error {
+	// Claim a batch by pushing its next attempt past the lease, then send outside the transaction
+	var deliveries []models.WebhookDelivery
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", webhook.StatusPending, now).
+			Order("next_attempt_at").Limit(deliveryBatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(deliveryLease)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if err := attemptWebhook(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attemptWebhook Make one request for a delivery and record the outcome
+func attemptWebhook(ctx context.Context, delivery models.WebhookDelivery) error {
+	var event models.OutboxEvent
+	var endpoint models.WebhookEndpoint
+	if err := database.DB.First(&event, delivery.EventID).Error; err != nil {
+		return err
+	}
+	if err := database.DB.First(&endpoint, delivery.EndpointID).Error; err != nil {
+		// Deleted endpoints drop their pending deliveries
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return database.DB.Model(&delivery).Updates(map[string]interface{}{
+				"status":          webhook.StatusFailed,
+				"next_attempt_at": nil,
+				"last_error":      "endpoint deleted",
+			}).Error
+		}
+		return err
+	}
+
+	result, sendErr := webhookClient.Deliver(ctx, endpoint.URL, endpoint.Secret, webhook.Envelope{
+		ID:        webhook.EventID(event.ID),
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Payload),
+	})
+
+	attempt := models.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		StatusCode: result.StatusCode,
+		DurationMs: result.Duration.Milliseconds(),
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	} else if !result.Succeeded() {
+		attempt.Error = fmt.Sprintf("unexpected status %d", result.StatusCode)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":         delivery.Attempts + 1,
+		"last_status_code": result.StatusCode,
+		"last_error":       attempt.Error,
+	}
+	switch {
+	case attempt.Error == "":
+		updates["status"] = webhook.StatusSucceeded
+		updates["delivered_at"] = now
+		updates["next_attempt_at"] = nil
+	case delivery.Attempts+1 >= webhook.MaxAttempts:
+		updates["status"] = webhook.StatusFailed
+		updates["next_attempt_at"] = nil
+	default:
+		updates["next_attempt_at"] = now.Add(webhook.Backoff(delivery.Attempts + 1))
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&delivery).Updates(updates).Error
+	})
+}
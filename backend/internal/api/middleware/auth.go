@@ -4,6 +4,9 @@ import (
 	"net/http"
 	"strings"
 
+	"reservation-platform-sample/internal/domain/models"
+	"reservation-platform-sample/internal/infrastructure/database"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -20,41 +23,84 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Extract Bearer token
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
+		userID, errMessage := userIDFromHeader(authHeader)
+		if errMessage != "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errMessage})
 			c.Abort()
 			return
 		}
 
-		// Verify token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
+		c.Set("userID", userID)
+		c.Next()
+	}
+}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
+// OptionalAuthMiddleware Set the user ID when a valid token is sent, otherwise continue anonymously
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			if userID, errMessage := userIDFromHeader(authHeader); errMessage == "" {
+				c.Set("userID", userID)
+			}
 		}
+		c.Next()
+	}
+}
 
-		// Set user ID in context
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+// userIDFromHeader Verify the bearer token and extract the user ID (returns an error message on failure)
+func userIDFromHeader(authHeader string) (uint, string) {
+	// Extract Bearer token
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		return 0, "Invalid authorization format"
+	}
+
+	// Verify token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	})
+
+	if err != nil || !token.Valid {
+		return 0, "Invalid token"
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, "Invalid token claims"
+	}
+
+	// Scoped tokens (manage links, email verification) cannot be used for login
+	if _, scoped := claims["purpose"]; scoped {
+		return 0, "Invalid token"
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, "Invalid user ID in token"
+	}
+
+	return uint(userID), ""
+}
+
+// RequireRole Allow only users with one of the given roles (must run after AuthMiddleware)
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		if err := database.DB.First(&user, c.GetUint("userID")).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			c.Abort()
 			return
 		}
 
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
-			c.Abort()
-			return
+		for _, role := range roles {
+			if user.Role == role {
+				c.Set("userRole", user.Role)
+				c.Next()
+				return
+			}
 		}
 
-		c.Set("userID", uint(userID))
-		c.Next()
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
 	}
 }
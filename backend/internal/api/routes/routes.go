@@ -26,12 +26,35 @@ func SetupRoutes() *gin.Engine {
 		{
 			auth.POST("/register", handlers.Register)
 			auth.POST("/login", handlers.Login)
+			auth.POST("/verify-email", handlers.VerifyEmail)
 		}
 
+		// Guest reservations (access via signed manage link token)
+		guest := api.Group("/guest")
+		{
+			guest.POST("/reservations", handlers.CreateGuestReservation)
+			guest.GET("/reservations/:token", handlers.GetGuestReservation)
+			guest.DELETE("/reservations/:token", handlers.CancelGuestReservation)
+			guest.POST("/reservations/:token/confirm", handlers.ConfirmGuestReservation)
+			guest.GET("/reservations/:token/ics", handlers.GetGuestReservationICS)
+		}
+
+		// Payment gateway notifications (verified by signature)
+		api.POST("/payments/webhook", handlers.PaymentWebhook)
+
+		// Private calendar subscription feeds (the token is the secret)
+		api.GET("/calendar/:token", handlers.GetCalendarFeed)
+
+		// Gift card balance inquiry (the code is the secret)
+		api.GET("/gift-cards/:code", handlers.GetGiftCardBalance)
+
 		// Salon related (no authentication required)
-		api.GET("/salons", handlers.GetSalons)
-		api.GET("/salons/:id", handlers.GetSalon)
-		api.GET("/salons/:salon_id/slots", handlers.GetAvailableSlots)
+		api.GET("/salons", middleware.OptionalAuthMiddleware(), handlers.GetSalons)
+		api.GET("/salons/:id", middleware.OptionalAuthMiddleware(), handlers.GetSalon)
+		api.GET("/salons/:id/slots", handlers.GetAvailableSlots)
+		api.GET("/salons/:id/slots/stream", handlers.StreamAvailableSlots)
+		api.GET("/salons/:id/reviews", handlers.GetSalonReviews)
+		api.GET("/salons/:id/staff/:staff_id/reviews", handlers.GetStaffReviews)
 
 		// Routes that require authentication
 		protected := api.Group("")
@@ -46,13 +69,116 @@ func SetupRoutes() *gin.Engine {
 			protected.POST("/reservations", handlers.CreateReservation)
 			protected.PUT("/reservations/:id", handlers.UpdateReservation)
 			protected.DELETE("/reservations/:id", handlers.DeleteReservation)
+			protected.GET("/reservations/:id/ics", handlers.GetReservationICS)
+
+			// Slot holds while completing the booking form
+			protected.POST("/salons/:id/holds", handlers.CreateSlotHold)
+			protected.DELETE("/holds/:token", handlers.ReleaseSlotHold)
+
+			// Calendar subscription
+			protected.GET("/calendar/feed", handlers.GetMyCalendarFeed)
+			protected.POST("/calendar/feed/reset", handlers.ResetMyCalendarFeed)
+
+			// Favorite related
+			protected.GET("/favorites/salons", handlers.GetFavoriteSalons)
+			protected.GET("/favorites/staff", handlers.GetFavoriteStaff)
+			protected.PUT("/favorites/salons/:id", handlers.AddFavoriteSalon)
+			protected.DELETE("/favorites/salons/:id", handlers.RemoveFavoriteSalon)
+			protected.PUT("/favorites/staff/:id", handlers.AddFavoriteStaff)
+			protected.DELETE("/favorites/staff/:id", handlers.RemoveFavoriteStaff)
+
+			// Notification preferences
+			protected.GET("/notifications/preferences", handlers.GetNotificationPreferences)
+			protected.PUT("/notifications/preferences", handlers.UpdateNotificationPreferences)
+
+			// Loyalty points
+			protected.GET("/points", handlers.GetPoints)
+			protected.GET("/points/history", handlers.GetPointHistory)
+
+			// Review related
+			protected.POST("/reservations/:id/review", handlers.CreateReview)
+			protected.POST("/reviews/:id/flag", handlers.FlagReview)
+
+			// Invitations to link the account to a salon's staff member
+			protected.GET("/staff-invitations", handlers.GetStaffInvitations)
+			protected.POST("/staff-invitations/:id/accept", handlers.AcceptStaffInvitation)
+			protected.DELETE("/staff-invitations/:id", handlers.DeclineStaffInvitation)
 
-			// Admin only routes (TODO: Add admin authentication middleware)
+			// Staff only routes
+			staff := protected.Group("/staff/me")
+			staff.Use(middleware.RequireRole("staff"))
+			{
+				staff.GET("/agenda", handlers.GetMyAgenda)
+				staff.POST("/reservations/:id/check-in", handlers.CheckInReservation)
+				staff.POST("/reservations/:id/complete", handlers.CompleteReservation)
+				staff.POST("/reservations/:id/no-show", handlers.MarkNoShow)
+				staff.GET("/calendar/feed", handlers.GetMyStaffCalendarFeed)
+				staff.POST("/calendar/feed/reset", handlers.ResetMyStaffCalendarFeed)
+			}
+
+			// Admin only routes
 			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireRole("admin"))
 			{
 				admin.POST("/salons", handlers.CreateSalon)
 				admin.PUT("/salons/:id", handlers.UpdateSalon)
 				admin.DELETE("/salons/:id", handlers.DeleteSalon)
+				admin.POST("/search/reindex", handlers.ReindexSearch)
+				admin.GET("/cache", handlers.GetCacheStats)
+				admin.DELETE("/cache", handlers.FlushCache)
+
+				// Review management
+				admin.POST("/reviews/:id/reply", handlers.ReplyReview)
+				admin.GET("/reviews/flagged", handlers.GetFlaggedReviews)
+				admin.PUT("/reviews/:id/moderation", handlers.ModerateReview)
+
+				// Salon reservation management
+				admin.GET("/salons/:id/reservations", handlers.GetSalonReservations)
+				admin.POST("/salons/:id/reservations", handlers.CreateSalonReservation)
+				admin.GET("/salons/:id/reservations/:reservation_id/audits", handlers.GetReservationAudits)
+				admin.GET("/salons/:id/live", handlers.StreamSalonActivity)
+
+				// Promotions and coupons
+				admin.GET("/salons/:id/promotions", handlers.GetPromotions)
+				admin.POST("/salons/:id/promotions", handlers.CreatePromotion)
+				admin.PUT("/salons/:id/promotions/:promotion_id", handlers.UpdatePromotion)
+				admin.DELETE("/salons/:id/promotions/:promotion_id", handlers.DeletePromotion)
+
+				// Price rules by stylist level, weekday and time of day
+				admin.GET("/salons/:id/price-rules", handlers.GetPriceRules)
+				admin.POST("/salons/:id/price-rules", handlers.CreatePriceRule)
+				admin.PUT("/salons/:id/price-rules/:rule_id", handlers.UpdatePriceRule)
+				admin.DELETE("/salons/:id/price-rules/:rule_id", handlers.DeletePriceRule)
+
+				// Gift cards
+				admin.GET("/salons/:id/gift-cards", handlers.GetGiftCards)
+				admin.POST("/salons/:id/gift-cards", handlers.IssueGiftCard)
+				admin.GET("/salons/:id/gift-cards/liability", handlers.GetGiftCardLiability)
+				admin.GET("/salons/:id/gift-cards/:card_id", handlers.GetGiftCard)
+
+				// Outgoing webhooks
+				admin.GET("/salons/:id/webhooks", handlers.GetWebhookEndpoints)
+				admin.POST("/salons/:id/webhooks", handlers.CreateWebhookEndpoint)
+				admin.PUT("/salons/:id/webhooks/:webhook_id", handlers.UpdateWebhookEndpoint)
+				admin.DELETE("/salons/:id/webhooks/:webhook_id", handlers.DeleteWebhookEndpoint)
+				admin.GET("/salons/:id/webhooks/:webhook_id/deliveries", handlers.GetWebhookDeliveries)
+				admin.GET("/salons/:id/webhooks/:webhook_id/deliveries/:delivery_id", handlers.GetWebhookDelivery)
+				admin.POST("/salons/:id/webhooks/:webhook_id/deliveries/:delivery_id/redeliver", handlers.RedeliverWebhook)
+
+				admin.PUT("/staff/:id/user", handlers.InviteStaffUser)
+
+				// Staff time-off and calendar blocks
+				admin.GET("/staff/:id/blocks", handlers.GetStaffBlocks)
+				admin.POST("/staff/:id/blocks", handlers.CreateStaffBlock)
+				admin.DELETE("/staff/:id/blocks/:block_id", handlers.DeleteStaffBlock)
+				admin.GET("/staff/:id/blocks/:block_id/conflicts", handlers.GetStaffBlockConflicts)
+				admin.POST("/staff/:id/blocks/:block_id/reschedule", handlers.RescheduleStaffBlockConflicts)
+
+				// External calendar busy times imported as staff blocks
+				admin.GET("/staff/:id/calendar-source", handlers.GetStaffCalendarSource)
+				admin.PUT("/staff/:id/calendar-source", handlers.PutStaffCalendarSource)
+				admin.DELETE("/staff/:id/calendar-source", handlers.DeleteStaffCalendarSource)
+				admin.POST("/staff/:id/calendar-source/sync", handlers.SyncStaffCalendarSource)
 			}
 		}
 	}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// CalendarFeed Private iCalendar subscription URL of a customer or a stylist.
+// Anyone with the token can read the feed, so resetting it replaces the token.
+type CalendarFeed struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Token     string    `json:"-" gorm:"uniqueIndex;not null"`
+	UserID    *uint     `json:"user_id,omitempty" gorm:"uniqueIndex"`  // Customer feed
+	StaffID   *uint     `json:"staff_id,omitempty" gorm:"uniqueIndex"` // Stylist feed
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// StaffCalendarSource External iCalendar a stylist keeps (e.g. for another salon). Its busy times are
+// imported periodically as staff blocks with source ics, replacing the previous import.
+type StaffCalendarSource struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	StaffID    uint       `json:"staff_id" gorm:"uniqueIndex;not null"`
+	URL        string     `json:"url" gorm:"not null"` // http(s), webcal, or file:// under the local calendar directory
+	SyncedAt   *time.Time `json:"synced_at"`           // Last successful import
+	AttemptAt  *time.Time `json:"attempt_at"`          // Last import attempt
+	LastError  string     `json:"last_error,omitempty"`
+	BusyBlocks int        `json:"busy_blocks"` // Blocks created by the last import
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
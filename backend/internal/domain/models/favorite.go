@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// FavoriteSalon Salon saved by a user
+type FavoriteSalon struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_favorite_salon"`
+	SalonID   uint      `json:"salon_id" gorm:"not null;uniqueIndex:idx_favorite_salon"`
+	Salon     *Salon    `json:"salon,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FavoriteStaff Stylist saved by a user
+type FavoriteStaff struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_favorite_staff"`
+	StaffID   uint      `json:"staff_id" gorm:"not null;uniqueIndex:idx_favorite_staff"`
+	Staff     *Staff    `json:"staff,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GiftCard Prepaid balance sold by a salon, spent on its reservations
+type GiftCard struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	SalonID        uint       `json:"salon_id" gorm:"not null;index"`
+	Code           string     `json:"code" gorm:"not null;uniqueIndex"`
+	InitialAmount  int        `json:"initial_amount" gorm:"not null"`
+	Balance        int        `json:"balance" gorm:"not null"`
+	ExpiresAt      *time.Time `json:"expires_at"` // Nil for no expiry
+	PurchaserName  string     `json:"purchaser_name"`
+	RecipientName  string     `json:"recipient_name"`
+	RecipientEmail string     `json:"recipient_email"`
+	Note           string     `json:"note"`
+	IssuedByUserID uint       `json:"issued_by_user_id"`
+	Salon          *Salon     `json:"salon,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// GiftCardTransaction Change to a gift card balance
+type GiftCardTransaction struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	GiftCardID    uint      `json:"gift_card_id" gorm:"not null;index"`
+	ReservationID *uint     `json:"reservation_id,omitempty" gorm:"index"`
+	Type          string    `json:"type" gorm:"not null"`    // issue, redeem or refund
+	Amount        int       `json:"amount" gorm:"not null"`  // Negative when spent
+	Balance       int       `json:"balance" gorm:"not null"` // Card balance after the transaction
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Expired Whether the card can no longer be used at a time
+func (g *GiftCard) Expired(at time.Time) bool {
+	return g.ExpiresAt != nil && !at.Before(*g.ExpiresAt)
+}
+
+// BeforeSave Validate the amounts
+func (g *GiftCard) BeforeSave(tx *gorm.DB) error {
+	if g.InitialAmount <= 0 {
+		return errors.New("gift card amount must be positive")
+	}
+	if g.Balance < 0 || g.Balance > g.InitialAmount {
+		return errors.New("gift card balance must be between 0 and the initial amount")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// PointEntry Loyalty points ledger entry. Entries are append-only; only Remaining changes as credits are spent.
+type PointEntry struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	Type          string     `json:"type" gorm:"not null;uniqueIndex:idx_point_entries_reservation_type"` // earn, redeem, refund or expire
+	Points        int        `json:"points" gorm:"not null"`                                              // Positive for credits, negative for debits
+	Balance       int        `json:"balance" gorm:"not null"`                                             // User balance after the entry
+	Remaining     int        `json:"remaining,omitempty"`                                                 // Unspent part of a credit
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`                                                // Credits only
+	ReservationID *uint      `json:"reservation_id,omitempty" gorm:"uniqueIndex:idx_point_entries_reservation_type"`
+	CreditID      *uint      `json:"credit_id,omitempty"` // Credit an expire entry closes
+	Description   string     `json:"description"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// PointRedemption Part of a credit spent by a reservation; given back to the same credit,
+// with its original expiry, when the reservation is cancelled
+type PointRedemption struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ReservationID uint      `json:"reservation_id" gorm:"not null;index"`
+	CreditID      uint      `json:"credit_id" gorm:"not null"`
+	Points        int       `json:"points" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
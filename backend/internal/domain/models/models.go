@@ -1,95 +1,225 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Salon struct {
-	ID           uint                   `json:"id" gorm:"primaryKey"`
-	Name         string                 `json:"name" gorm:"not null"`
-	Description  string                 `json:"description"`
-	Address      string                 `json:"address" gorm:"not null"`
-	Phone        string                 `json:"phone"`
-	Email        string                 `json:"email"`
-	Website      string                 `json:"website"`
-	ImageURL     string                 `json:"image_url"`
-	OpeningHours map[string]interface{} `json:"opening_hours" gorm:"type:jsonb"`
-	Latitude     float64                `json:"latitude"`
-	Longitude    float64                `json:"longitude"`
-	Staff        []Staff                `json:"staff,omitempty" gorm:"foreignKey:SalonID"`
-	Services     []Service              `json:"services,omitempty" gorm:"foreignKey:SalonID"`
-	CreatedAt    time.Time              `json:"created_at"`
-	UpdatedAt    time.Time              `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt         `json:"-" gorm:"index"`
+	ID                  uint                   `json:"id" gorm:"primaryKey"`
+	Name                string                 `json:"name" gorm:"not null"`
+	NameKana            string                 `json:"name_kana"` // Reading of the name, used by search
+	Description         string                 `json:"description"`
+	Address             string                 `json:"address" gorm:"not null"`
+	Phone               string                 `json:"phone"`
+	Email               string                 `json:"email"`
+	Website             string                 `json:"website"`
+	ImageURL            string                 `json:"image_url"`
+	OpeningHours        map[string]interface{} `json:"opening_hours" gorm:"type:jsonb"`
+	Latitude            float64                `json:"latitude" gorm:"index:idx_salons_location"`
+	Longitude           float64                `json:"longitude" gorm:"index:idx_salons_location"`
+	DistanceKm          *float64               `json:"distance_km,omitempty" gorm:"->;-:migration"` // Set by location search
+	BufferBeforeMinutes int                    `json:"buffer_before_minutes"`                       // Preparation time before each appointment
+	BufferAfterMinutes  int                    `json:"buffer_after_minutes"`                        // Cleanup time after each appointment
+	PaymentPolicy       string                 `json:"payment_policy"`                              // "" (pay at the salon), deposit or prepay
+	DepositPercent      int                    `json:"deposit_percent"`                             // Share of the price charged with the deposit policy
+	FreeCancelHours     int                    `json:"free_cancel_hours"`                           // Full refund when cancelled at least this long before the start
+	CancelRefundPercent int                    `json:"cancel_refund_percent"`                       // Share refunded on later cancellations
+	AverageRating       float64                `json:"average_rating"`                              // Aggregated from visible reviews
+	ReviewCount         int                    `json:"review_count"`
+	IsFavorite          *bool                  `json:"is_favorite,omitempty" gorm:"-"` // Set only for authenticated requests
+	SearchRank          *float64               `json:"search_rank,omitempty" gorm:"->;-:migration"`
+	Highlights          map[string]string      `json:"highlights,omitempty" gorm:"-"` // Snippets with <mark> around search matches
+	Staff               []Staff                `json:"staff,omitempty" gorm:"foreignKey:SalonID"`
+	Services            []Service              `json:"services,omitempty" gorm:"foreignKey:SalonID"`
+	CreatedAt           time.Time              `json:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt         `json:"-" gorm:"index"`
 }
 
 type Staff struct {
-	ID              uint                   `json:"id" gorm:"primaryKey"`
-	SalonID         uint                   `json:"salon_id" gorm:"not null"`
-	Name            string                 `json:"name" gorm:"not null"`
-	Description     string                 `json:"description"`
-	ImageURL        string                 `json:"image_url"`
-	Specialties     []string               `json:"specialties" gorm:"type:text[]"`
-	ExperienceYears int                    `json:"experience_years"`
-	WorkingHours    map[string]interface{} `json:"working_hours" gorm:"type:jsonb"`
-	IsActive        bool                   `json:"is_active" gorm:"default:true"`
-	Salon           *Salon                 `json:"salon,omitempty"`
-	Reservations    []Reservation          `json:"reservations,omitempty" gorm:"foreignKey:StaffID"`
-	CreatedAt       time.Time              `json:"created_at"`
-	UpdatedAt       time.Time              `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt         `json:"-" gorm:"index"`
+	ID                  uint                   `json:"id" gorm:"primaryKey"`
+	SalonID             uint                   `json:"salon_id" gorm:"not null"`
+	UserID              *uint                  `json:"user_id" gorm:"uniqueIndex"`             // Linked staff account
+	InvitedUserID       *uint                  `json:"invited_user_id,omitempty" gorm:"index"` // Account invited to link, until it accepts
+	Name                string                 `json:"name" gorm:"not null"`
+	Description         string                 `json:"description"`
+	ImageURL            string                 `json:"image_url"`
+	Specialties         []string               `json:"specialties" gorm:"type:text[]"`
+	ExperienceYears     int                    `json:"experience_years"`
+	Level               string                 `json:"level"` // junior, senior or director; derived from ExperienceYears when empty
+	WorkingHours        map[string]interface{} `json:"working_hours" gorm:"type:jsonb"`
+	BufferBeforeMinutes int                    `json:"buffer_before_minutes"`
+	BufferAfterMinutes  int                    `json:"buffer_after_minutes"`
+	AverageRating       float64                `json:"average_rating"` // Aggregated from visible reviews
+	ReviewCount         int                    `json:"review_count"`
+	IsActive            bool                   `json:"is_active" gorm:"default:true"`
+	Salon               *Salon                 `json:"salon,omitempty"`
+	Reservations        []Reservation          `json:"reservations,omitempty" gorm:"foreignKey:StaffID"`
+	CreatedAt           time.Time              `json:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt         `json:"-" gorm:"index"`
 }
 
 type Service struct {
+	ID                      uint           `json:"id" gorm:"primaryKey"`
+	SalonID                 uint           `json:"salon_id" gorm:"not null"`
+	Name                    string         `json:"name" gorm:"not null"`
+	Description             string         `json:"description"`
+	Price                   int            `json:"price" gorm:"not null"`            // Price (in yen)
+	DurationMinutes         int            `json:"duration_minutes" gorm:"not null"` // Duration (in minutes)
+	ProcessingOffsetMinutes int            `json:"processing_offset_minutes"`        // Active minutes before the processing phase starts
+	ProcessingMinutes       int            `json:"processing_minutes"`               // Processing minutes (e.g. colour developing) where the stylist is free
+	BufferBeforeMinutes     int            `json:"buffer_before_minutes"`
+	BufferAfterMinutes      int            `json:"buffer_after_minutes"`
+	Category                string         `json:"category"`
+	IsActive                bool           `json:"is_active" gorm:"default:true"`
+	Salon                   *Salon         `json:"salon,omitempty"`
+	Reservations            []Reservation  `json:"reservations,omitempty" gorm:"foreignKey:ServiceID"`
+	CreatedAt               time.Time      `json:"created_at"`
+	UpdatedAt               time.Time      `json:"updated_at"`
+	DeletedAt               gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type User struct {
 	ID              uint           `json:"id" gorm:"primaryKey"`
-	SalonID         uint           `json:"salon_id" gorm:"not null"`
+	Email           string         `json:"email" gorm:"uniqueIndex;not null"`
+	PasswordHash    string         `json:"-" gorm:"not null"`
 	Name            string         `json:"name" gorm:"not null"`
-	Description     string         `json:"description"`
-	Price           int            `json:"price" gorm:"not null"`            // Price (in yen)
-	DurationMinutes int            `json:"duration_minutes" gorm:"not null"` // Duration (in minutes)
-	Category        string         `json:"category"`
-	IsActive        bool           `json:"is_active" gorm:"default:true"`
-	Salon           *Salon         `json:"salon,omitempty"`
-	Reservations    []Reservation  `json:"reservations,omitempty" gorm:"foreignKey:ServiceID"`
+	Phone           string         `json:"phone"`
+	DateOfBirth     *time.Time     `json:"date_of_birth"`
+	Gender          string         `json:"gender"`
+	Role            string         `json:"role" gorm:"default:'customer'"` // customer, admin, staff
+	SalonID         *uint          `json:"salon_id,omitempty"`             // Salon managed by an admin; nil for platform admins
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	Reservations    []Reservation  `json:"reservations,omitempty" gorm:"foreignKey:UserID"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
-type User struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	Email        string         `json:"email" gorm:"uniqueIndex;not null"`
-	PasswordHash string         `json:"-" gorm:"not null"`
-	Name         string         `json:"name" gorm:"not null"`
-	Phone        string         `json:"phone"`
-	DateOfBirth  *time.Time     `json:"date_of_birth"`
-	Gender       string         `json:"gender"`
-	Role         string         `json:"role" gorm:"default:'customer'"` // customer, admin, staff
-	Reservations []Reservation  `json:"reservations,omitempty" gorm:"foreignKey:UserID"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+type Reservation struct {
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	SalonID             uint           `json:"salon_id" gorm:"not null"`
+	StaffID             uint           `json:"staff_id" gorm:"not null"`
+	UserID              *uint          `json:"user_id"` // Nil for guest reservations
+	ServiceID           uint           `json:"service_id" gorm:"not null"`
+	ReservationDate     time.Time      `json:"reservation_date" gorm:"not null"`
+	StartTime           time.Time      `json:"start_time" gorm:"not null"`
+	EndTime             time.Time      `json:"end_time" gorm:"not null"`
+	ProcessingStartTime *time.Time     `json:"processing_start_time,omitempty"` // Copied from the service; staff is free until ProcessingEndTime
+	ProcessingEndTime   *time.Time     `json:"processing_end_time,omitempty"`
+	BufferBeforeMinutes int            `json:"buffer_before_minutes"` // Effective buffers copied at booking time
+	BufferAfterMinutes  int            `json:"buffer_after_minutes"`
+	Status              string         `json:"status" gorm:"default:'confirmed'"` // confirmed, checked_in, cancelled, completed, no_show
+	CheckedInAt         *time.Time     `json:"checked_in_at,omitempty"`
+	CompletedAt         *time.Time     `json:"completed_at,omitempty"`
+	CustomerConfirmedAt *time.Time     `json:"customer_confirmed_at,omitempty"` // Customer confirmed attendance from a reminder
+	CalendarSequence    int            `json:"calendar_sequence"`               // iCalendar SEQUENCE; incremented when the time, stylist or status changes
+	Notes               string         `json:"notes"`
+	GuestName           string         `json:"guest_name,omitempty"`
+	GuestEmail          string         `json:"guest_email,omitempty" gorm:"index"`
+	GuestPhone          string         `json:"guest_phone,omitempty"`
+	TotalPrice          int            `json:"total_price" gorm:"not null"`
+	PriceBreakdown      []PriceLine    `json:"price_breakdown" gorm:"type:jsonb;serializer:json"` // Service price and discounts making up TotalPrice
+	CouponCode          string         `json:"coupon_code,omitempty" gorm:"-"`                    // Coupon to apply (request only)
+	PointsUsed          int            `json:"points_used"`                                       // Loyalty points redeemed at booking time
+	GiftCardCode        string         `json:"gift_card_code,omitempty" gorm:"-"`                 // Gift card to pay with (request only)
+	GiftCardID          *uint          `json:"gift_card_id,omitempty"`
+	GiftCardAmount      int            `json:"gift_card_amount"`                  // Part of TotalPrice paid with the gift card
+	PaymentMethod       string         `json:"payment_method,omitempty" gorm:"-"` // Gateway token for the deposit or prepayment (request only)
+	HoldToken           string         `json:"hold_token,omitempty" gorm:"-"`     // Slot hold used up by the booking (request only)
+	Payments            []Payment      `json:"payments,omitempty"`
+	Salon               *Salon         `json:"salon,omitempty"`
+	Staff               *Staff         `json:"staff,omitempty"`
+	User                *User          `json:"user,omitempty"`
+	Service             *Service       `json:"service,omitempty"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
-type Reservation struct {
-	ID              uint           `json:"id" gorm:"primaryKey"`
-	SalonID         uint           `json:"salon_id" gorm:"not null"`
-	StaffID         uint           `json:"staff_id" gorm:"not null"`
-	UserID          uint           `json:"user_id" gorm:"not null"`
-	ServiceID       uint           `json:"service_id" gorm:"not null"`
-	ReservationDate time.Time      `json:"reservation_date" gorm:"not null"`
-	StartTime       time.Time      `json:"start_time" gorm:"not null"`
-	EndTime         time.Time      `json:"end_time" gorm:"not null"`
-	Status          string         `json:"status" gorm:"default:'confirmed'"` // confirmed, cancelled, completed
-	Notes           string         `json:"notes"`
-	TotalPrice      int            `json:"total_price" gorm:"not null"`
-	Salon           *Salon         `json:"salon,omitempty"`
-	Staff           *Staff         `json:"staff,omitempty"`
-	User            *User          `json:"user,omitempty"`
-	Service         *Service       `json:"service,omitempty"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+// PriceLine One item of a reservation's price breakdown
+type PriceLine struct {
+	Type      string `json:"type"` // service, staff_level, surcharge, promotion, coupon, points or adjustment
+	Label     string `json:"label"`
+	Amount    int    `json:"amount"`              // Yen; negative for discounts
+	Reference uint   `json:"reference,omitempty"` // ID of the service, promotion, ... behind the line
+}
+
+// BeforeSave Validate buffer times
+func (s *Salon) BeforeSave(tx *gorm.DB) error {
+	return validateBuffers(s.BufferBeforeMinutes, s.BufferAfterMinutes)
+}
+
+// BeforeSave Validate buffer times and the level
+func (s *Staff) BeforeSave(tx *gorm.DB) error {
+	if err := validateBuffers(s.BufferBeforeMinutes, s.BufferAfterMinutes); err != nil {
+		return err
+	}
+	if !ValidStaffLevel(s.Level) {
+		return errors.New("invalid staff level")
+	}
+	return nil
+}
+
+// Stylist levels
+const (
+	StaffLevelJunior   = "junior"
+	StaffLevelSenior   = "senior"
+	StaffLevelDirector = "director"
+)
+
+// Years of experience from which a stylist without an explicit level is senior or director
+const (
+	seniorFromYears   = 3
+	directorFromYears = 10
+)
+
+// ValidStaffLevel Whether a stylist level is known (empty means derived from experience)
+func ValidStaffLevel(level string) bool {
+	switch level {
+	case "", StaffLevelJunior, StaffLevelSenior, StaffLevelDirector:
+		return true
+	}
+	return false
+}
+
+// PriceLevel Level used for pricing: the explicit level, or one derived from years of experience
+func (s *Staff) PriceLevel() string {
+	if s.Level != "" {
+		return s.Level
+	}
+	switch {
+	case s.ExperienceYears >= directorFromYears:
+		return StaffLevelDirector
+	case s.ExperienceYears >= seniorFromYears:
+		return StaffLevelSenior
+	default:
+		return StaffLevelJunior
+	}
+}
+
+// BeforeSave Validate the service phase layout and buffer times
+func (s *Service) BeforeSave(tx *gorm.DB) error {
+	if err := validateBuffers(s.BufferBeforeMinutes, s.BufferAfterMinutes); err != nil {
+		return err
+	}
+	if s.ProcessingOffsetMinutes < 0 || s.ProcessingMinutes < 0 {
+		return errors.New("processing minutes must not be negative")
+	}
+	if s.ProcessingMinutes > 0 && s.ProcessingOffsetMinutes+s.ProcessingMinutes >= s.DurationMinutes {
+		return errors.New("processing phase must end before the service ends")
+	}
+	return nil
+}
+
+// validateBuffers Check that buffer times are not negative
+func validateBuffers(before, after int) error {
+	if before < 0 || after < 0 {
+		return errors.New("buffer minutes must not be negative")
+	}
+	return nil
 }
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStaffPriceLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		staff Staff
+		want  string
+	}{
+		{"explicit level", Staff{Level: StaffLevelDirector, ExperienceYears: 1}, StaffLevelDirector},
+		{"new stylist", Staff{ExperienceYears: 0}, StaffLevelJunior},
+		{"senior from 3 years", Staff{ExperienceYears: 3}, StaffLevelSenior},
+		{"director from 10 years", Staff{ExperienceYears: 10}, StaffLevelDirector},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.staff.PriceLevel(); got != tt.want {
+				t.Errorf("PriceLevel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidStaffLevel(t *testing.T) {
+	for _, level := range []string{"", StaffLevelJunior, StaffLevelSenior, StaffLevelDirector} {
+		if !ValidStaffLevel(level) {
+			t.Errorf("ValidStaffLevel(%q) = false, want true", level)
+		}
+	}
+	if ValidStaffLevel("master") {
+		t.Error(`ValidStaffLevel("master") = true, want false`)
+	}
+}
+
+func TestPriceRuleBeforeSave(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    PriceRule
+		wantErr bool
+	}{
+		{"percent surcharge", PriceRule{AdjustmentType: AmountPercent, AdjustmentValue: 20}, false},
+		{"free", PriceRule{AdjustmentType: AmountPercent, AdjustmentValue: -100}, false},
+		{"below zero", PriceRule{AdjustmentType: AmountPercent, AdjustmentValue: -101}, true},
+		{"fixed reduction for seniors", PriceRule{StaffLevel: StaffLevelSenior, AdjustmentType: AmountFixed, AdjustmentValue: -500}, false},
+		{"zero", PriceRule{AdjustmentType: AmountFixed, AdjustmentValue: 0}, true},
+		{"unknown type", PriceRule{AdjustmentType: "double", AdjustmentValue: 2}, true},
+		{"unknown level", PriceRule{StaffLevel: "master", AdjustmentType: AmountFixed, AdjustmentValue: 500}, true},
+		{"weekday evenings", PriceRule{AdjustmentType: AmountPercent, AdjustmentValue: 10, Weekdays: []int{1, 2, 3, 4, 5}, StartTime: "17:00", EndTime: "21:00"}, false},
+		{"weekday out of range", PriceRule{AdjustmentType: AmountPercent, AdjustmentValue: 10, Weekdays: []int{7}}, true},
+		{"start without end", PriceRule{AdjustmentType: AmountPercent, AdjustmentValue: 10, StartTime: "17:00"}, true},
+		{"invalid time", PriceRule{AdjustmentType: AmountPercent, AdjustmentValue: 10, StartTime: "5pm", EndTime: "21:00"}, true},
+		{"end before start", PriceRule{AdjustmentType: AmountPercent, AdjustmentValue: 10, StartTime: "21:00", EndTime: "17:00"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.BeforeSave(nil); (err != nil) != tt.wantErr {
+				t.Errorf("BeforeSave() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPromotionBeforeSave(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name      string
+		promotion Promotion
+		wantErr   bool
+	}{
+		{"percent", Promotion{DiscountType: AmountPercent, DiscountValue: 10}, false},
+		{"whole price", Promotion{DiscountType: AmountPercent, DiscountValue: 100}, false},
+		{"zero percent", Promotion{DiscountType: AmountPercent, DiscountValue: 0}, true},
+		{"over 100 percent", Promotion{DiscountType: AmountPercent, DiscountValue: 101}, true},
+		{"fixed", Promotion{DiscountType: AmountFixed, DiscountValue: 500}, false},
+		{"negative fixed", Promotion{DiscountType: AmountFixed, DiscountValue: -500}, true},
+		{"unknown type", Promotion{DiscountType: "free", DiscountValue: 1}, true},
+		{"end before start", Promotion{DiscountType: AmountFixed, DiscountValue: 500, StartTime: "12:00", EndTime: "10:00"}, true},
+		{"no uses", Promotion{DiscountType: AmountFixed, DiscountValue: 500, MaxUses: &zero}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.promotion.BeforeSave(nil); (err != nil) != tt.wantErr {
+				t.Errorf("BeforeSave() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	coupon := Promotion{Code: " spring10 ", DiscountType: AmountPercent, DiscountValue: 10}
+	if err := coupon.BeforeSave(nil); err != nil || coupon.Code != "SPRING10" {
+		t.Errorf("BeforeSave() code = %q, %v, want SPRING10", coupon.Code, err)
+	}
+}
+
+func TestNotificationPreferenceBeforeSave(t *testing.T) {
+	if err := (&NotificationPreference{Push: true}).BeforeSave(nil); !errors.Is(err, ErrPushTokenRequired) {
+		t.Errorf("BeforeSave() without a push token error = %v, want %v", err, ErrPushTokenRequired)
+	}
+	if err := (&NotificationPreference{Push: true, PushToken: "token"}).BeforeSave(nil); err != nil {
+		t.Errorf("BeforeSave() with a push token error = %v", err)
+	}
+}
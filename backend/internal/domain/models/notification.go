@@ -0,0 +1,32 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrPushTokenRequired Push notifications enabled without a device token
+var ErrPushTokenRequired = errors.New("push_token is required for push notifications")
+
+// NotificationPreference Channels and language a user is notified in
+type NotificationPreference struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex"`
+	Email     bool      `json:"email"`
+	SMS       bool      `json:"sms"`    // Sent to the user's phone number
+	Push      bool      `json:"push"`   // Sent to PushToken
+	Locale    string    `json:"locale"` // ja or en
+	PushToken string    `json:"push_token"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeSave Validate the push settings (the locale is checked by the request binding)
+func (p *NotificationPreference) BeforeSave(tx *gorm.DB) error {
+	if p.Push && p.PushToken == "" {
+		return ErrPushTokenRequired
+	}
+	return nil
+}
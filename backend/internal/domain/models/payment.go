@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Payment Charge made through the payment gateway for a reservation
+type Payment struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	ReservationID     uint      `json:"reservation_id" gorm:"not null;index"`
+	Provider          string    `json:"provider" gorm:"not null"`
+	ProviderPaymentID string    `json:"-" gorm:"not null;uniqueIndex"`
+	Kind              string    `json:"kind" gorm:"not null"`   // deposit or prepay
+	Status            string    `json:"status" gorm:"not null"` // authorized, captured, partially_refunded, refunded, failed
+	Amount            int       `json:"amount" gorm:"not null"` // Authorized amount
+	CapturedAmount    int       `json:"captured_amount"`
+	RefundedAmount    int       `json:"refunded_amount"`
+	RefundDue         int       `json:"refund_due"` // Owed back after a cancellation; refunded until RefundedAmount reaches it
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// PaymentEvent Gateway webhook event, recorded once so redeliveries are ignored
+type PaymentEvent struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Provider   string    `json:"provider" gorm:"not null;uniqueIndex:idx_payment_events_provider_event"`
+	EventID    string    `json:"event_id" gorm:"not null;uniqueIndex:idx_payment_events_provider_event"`
+	Type       string    `json:"type" gorm:"not null"`
+	PaymentID  *uint     `json:"payment_id"` // Nil when the payment is unknown
+	Payload    string    `json:"payload" gorm:"type:jsonb"`
+	ReceivedAt time.Time `json:"received_at"`
+}
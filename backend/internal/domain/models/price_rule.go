@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceRule Salon price adjustment by stylist level and/or day and time of day.
+// Every matching rule is added to the service price.
+type PriceRule struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	SalonID         uint           `json:"salon_id" gorm:"not null;index"`
+	Name            string         `json:"name" gorm:"not null"`
+	StaffLevel      string         `json:"staff_level"`                                // junior, senior or director; empty for every stylist
+	Weekdays        []int          `json:"weekdays" gorm:"type:jsonb;serializer:json"` // 0 = Sunday; empty for every day
+	StartTime       string         `json:"start_time"`                                 // Appointment start window ("15:04"); empty for all day
+	EndTime         string         `json:"end_time"`
+	ServiceIDs      []uint         `json:"service_ids" gorm:"type:jsonb;serializer:json"` // Empty for every service
+	AdjustmentType  string         `json:"adjustment_type" gorm:"not null"`               // percent (of the service price) or fixed
+	AdjustmentValue int            `json:"adjustment_value" gorm:"not null"`              // Negative values reduce the price
+	IsActive        bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Adjustment and discount types of price rules and promotions
+const (
+	AmountPercent = "percent"
+	AmountFixed   = "fixed"
+)
+
+// BeforeSave Validate the stylist level, the adjustment and the day and time window
+func (r *PriceRule) BeforeSave(tx *gorm.DB) error {
+	if !ValidStaffLevel(r.StaffLevel) {
+		return errors.New("invalid staff level")
+	}
+
+	switch r.AdjustmentType {
+	case AmountPercent:
+		if r.AdjustmentValue < -100 {
+			return errors.New("percent adjustment must not reduce the price below zero")
+		}
+	case AmountFixed:
+	default:
+		return errors.New("invalid adjustment type")
+	}
+	if r.AdjustmentValue == 0 {
+		return errors.New("adjustment must not be zero")
+	}
+
+	return validateWindow(r.Weekdays, r.StartTime, r.EndTime)
+}
+
+// validateWindow Check the weekdays (0 = Sunday) and the "15:04" time of day window of a rule or promotion
+func validateWindow(weekdays []int, startTime, endTime string) error {
+	for _, day := range weekdays {
+		if day < 0 || day > 6 {
+			return errors.New("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+	if (startTime == "") != (endTime == "") {
+		return errors.New("start and end time must be set together")
+	}
+	if startTime != "" {
+		start, err := time.Parse("15:04", startTime)
+		if err != nil {
+			return errors.New("invalid start time")
+		}
+		end, err := time.Parse("15:04", endTime)
+		if err != nil {
+			return errors.New("invalid end time")
+		}
+		if !start.Before(end) {
+			return errors.New("start time must be before end time")
+		}
+	}
+	return nil
+}
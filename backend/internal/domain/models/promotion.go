@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Promotion Salon discount, applied automatically or with a coupon code
+type Promotion struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	SalonID            uint           `json:"salon_id" gorm:"not null;index;uniqueIndex:idx_promotions_salon_code,where:code <> '' AND deleted_at IS NULL"`
+	Name               string         `json:"name" gorm:"not null"`
+	Code               string         `json:"code" gorm:"index;uniqueIndex:idx_promotions_salon_code"` // Coupon code (case-insensitive, unique per salon); empty for automatic promotions
+	DiscountType       string         `json:"discount_type" gorm:"not null"`                           // percent or fixed
+	DiscountValue      int            `json:"discount_value" gorm:"not null"`                          // Percent or yen
+	FirstVisitOnly     bool           `json:"first_visit_only"`                                        // Only customers without earlier visits to the salon
+	Weekdays           []int          `json:"weekdays" gorm:"type:jsonb;serializer:json"`              // 0 = Sunday; empty for every day
+	StartTime          string         `json:"start_time"`                                              // Appointment start window ("15:04"); empty for all day
+	EndTime            string         `json:"end_time"`
+	ServiceIDs         []uint         `json:"service_ids" gorm:"type:jsonb;serializer:json"` // Empty for every service
+	ValidFrom          *time.Time     `json:"valid_from"`                                    // Appointments starting in [ValidFrom, ValidUntil)
+	ValidUntil         *time.Time     `json:"valid_until"`
+	MaxUses            *int           `json:"max_uses"`
+	MaxUsesPerCustomer *int           `json:"max_uses_per_customer"`
+	UsedCount          int            `json:"used_count"`
+	IsActive           bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// PromotionRedemption Use of a promotion by a reservation
+type PromotionRedemption struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	PromotionID   uint      `json:"promotion_id" gorm:"not null;index"`
+	ReservationID uint      `json:"reservation_id" gorm:"not null;index"`
+	UserID        *uint     `json:"user_id" gorm:"index"`
+	GuestEmail    string    `json:"guest_email,omitempty" gorm:"index"`
+	Amount        int       `json:"amount"` // Discount given
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BeforeSave Normalize the coupon code and validate the discount, day and time window, validity period and usage limits
+func (p *Promotion) BeforeSave(tx *gorm.DB) error {
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+
+	switch p.DiscountType {
+	case AmountPercent:
+		if p.DiscountValue <= 0 || p.DiscountValue > 100 {
+			return errors.New("percent discount must be between 1 and 100")
+		}
+	case AmountFixed:
+		if p.DiscountValue <= 0 {
+			return errors.New("fixed discount must be positive")
+		}
+	default:
+		return errors.New("invalid discount type")
+	}
+	if err := validateWindow(p.Weekdays, p.StartTime, p.EndTime); err != nil {
+		return err
+	}
+
+	if p.ValidFrom != nil && p.ValidUntil != nil && !p.ValidFrom.Before(*p.ValidUntil) {
+		return errors.New("valid_from must be before valid_until")
+	}
+	if (p.MaxUses != nil && *p.MaxUses < 1) || (p.MaxUsesPerCustomer != nil && *p.MaxUsesPerCustomer < 1) {
+		return errors.New("usage limits must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ReminderDelivery Reminder sent (or being sent) for a reservation. The unique index makes a reminder
+// claimable by a single scheduler instance; a new start time gets new reminders. A failed send keeps
+// its error and is claimed again for a retry by bumping Attempts, as is a claim left without an outcome
+// past its lease.
+type ReminderDelivery struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	ReservationID uint       `json:"reservation_id" gorm:"not null;uniqueIndex:idx_reminder_deliveries_claim"`
+	OffsetMinutes int        `json:"offset_minutes" gorm:"not null;uniqueIndex:idx_reminder_deliveries_claim"`
+	StartTime     time.Time  `json:"start_time" gorm:"not null;uniqueIndex:idx_reminder_deliveries_claim"` // Start time the reminder was for
+	SentAt        *time.Time `json:"sent_at"`
+	Error         string     `json:"error,omitempty"`
+	Attempts      int        `json:"attempts" gorm:"not null;default:1"`
+	ClaimedAt     time.Time  `json:"claimed_at" gorm:"not null;default:CURRENT_TIMESTAMP"` // Start of the latest attempt
+	CreatedAt     time.Time  `json:"created_at"`
+}
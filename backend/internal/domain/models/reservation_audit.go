@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ReservationAudit Record of an admin action on a reservation (bookings on behalf of customers, rule overrides)
+type ReservationAudit struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ReservationID uint      `json:"reservation_id" gorm:"not null;index"`
+	ActorUserID   uint      `json:"actor_user_id" gorm:"not null"`
+	Action        string    `json:"action" gorm:"not null"` // created_on_behalf, rule_override
+	Reason        string    `json:"reason"`
+	CreatedAt     time.Time `json:"created_at"`
+}
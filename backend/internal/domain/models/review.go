@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Review Customer review of a completed reservation
+type Review struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	ReservationID uint           `json:"reservation_id" gorm:"not null;uniqueIndex"` // One review per reservation
+	UserID        uint           `json:"user_id" gorm:"not null;index"`
+	SalonID       uint           `json:"salon_id" gorm:"not null;index"`
+	StaffID       uint           `json:"staff_id" gorm:"not null;index"`
+	Rating        int            `json:"rating" gorm:"not null"` // Salon rating (1-5)
+	StaffRating   *int           `json:"staff_rating"`           // Stylist rating (1-5)
+	Comment       string         `json:"comment"`
+	Reply         string         `json:"reply"` // Reply from the salon
+	RepliedAt     *time.Time     `json:"replied_at"`
+	IsFlagged     bool           `json:"is_flagged" gorm:"default:false"` // Reported for moderation
+	FlagReason    string         `json:"flag_reason,omitempty"`
+	IsHidden      bool           `json:"is_hidden" gorm:"default:false"` // Hidden by a moderator
+	User          *User          `json:"user,omitempty"`
+	Staff         *Staff         `json:"staff,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
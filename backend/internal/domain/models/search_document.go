@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// SearchDocument Full-text search index entry of a salon (salon, service and staff text as n-grams)
+type SearchDocument struct {
+	SalonID   uint      `json:"salon_id" gorm:"primaryKey;autoIncrement:false"`
+	Document  string    `json:"-" gorm:"type:tsvector;not null;index:idx_search_documents_document,type:gin"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
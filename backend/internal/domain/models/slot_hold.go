@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// SlotHold Slot kept for a customer while they complete the booking form. Unexpired holds make the
+// staff member busy for everyone else; booking with the hold token uses it up. Holds belong to a
+// logged-in customer, who may only have a few at once.
+type SlotHold struct {
+	ID                  uint      `json:"id" gorm:"primaryKey"`
+	Token               string    `json:"token" gorm:"uniqueIndex;not null"`
+	UserID              uint      `json:"user_id" gorm:"index"`
+	SalonID             uint      `json:"salon_id" gorm:"not null;index"`
+	StaffID             uint      `json:"staff_id" gorm:"not null;index"`
+	ServiceID           uint      `json:"service_id" gorm:"not null"`
+	StartTime           time.Time `json:"start_time" gorm:"not null"`
+	EndTime             time.Time `json:"end_time" gorm:"not null"`
+	BufferBeforeMinutes int       `json:"buffer_before_minutes"`
+	BufferAfterMinutes  int       `json:"buffer_after_minutes"`
+	ExpiresAt           time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt           time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StaffBlock Time during which a staff member cannot take bookings (sick day, training, lunch, ...)
+type StaffBlock struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	StaffID         uint           `json:"staff_id" gorm:"not null;index"`
+	StartTime       time.Time      `json:"start_time" gorm:"not null"`
+	EndTime         time.Time      `json:"end_time" gorm:"not null"`
+	AllDay          bool           `json:"all_day"`
+	Reason          string         `json:"reason"`
+	Recurrence      string         `json:"recurrence"`             // "", daily, weekly
+	RecurrenceUntil *time.Time     `json:"recurrence_until"`       // No end when nil
+	Source          string         `json:"source" gorm:"index"`    // "" when entered by the salon, ics when imported from the staff calendar source
+	ExternalUID     string         `json:"external_uid,omitempty"` // UID of the imported event
+	Staff           *Staff         `json:"staff,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEndpoint Salon URL receiving reservation events
+type WebhookEndpoint struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	SalonID     uint           `json:"salon_id" gorm:"not null;index"`
+	URL         string         `json:"url" gorm:"not null"`
+	Description string         `json:"description"`
+	Events      []string       `json:"events" gorm:"type:jsonb;serializer:json"` // Empty for every event
+	Secret      string         `json:"-" gorm:"not null"`                        // Signing secret, shown once on creation
+	IsActive    bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// OutboxEvent Event recorded in the same transaction as the change it describes, then fanned out to endpoints
+type OutboxEvent struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	SalonID      uint       `json:"salon_id" gorm:"not null;index"`
+	Type         string     `json:"type" gorm:"not null"`
+	Payload      string     `json:"payload" gorm:"type:jsonb;not null"` // Event data
+	DispatchedAt *time.Time `json:"dispatched_at" gorm:"index"`         // Deliveries created
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// WebhookDelivery Delivery of an event to an endpoint
+type WebhookDelivery struct {
+	ID             uint             `json:"id" gorm:"primaryKey"`
+	EndpointID     uint             `json:"endpoint_id" gorm:"not null;uniqueIndex:idx_webhook_deliveries_endpoint_event"`
+	EventID        uint             `json:"event_id" gorm:"not null;uniqueIndex:idx_webhook_deliveries_endpoint_event"`
+	EventType      string           `json:"event_type" gorm:"not null"`
+	Status         string           `json:"status" gorm:"not null;index"` // pending, succeeded or failed
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  *time.Time       `json:"next_attempt_at" gorm:"index"`
+	LastStatusCode int              `json:"last_status_code"`
+	LastError      string           `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time       `json:"delivered_at"`
+	Event          *OutboxEvent     `json:"event,omitempty" gorm:"foreignKey:EventID"`
+	AttemptLog     []WebhookAttempt `json:"attempt_log,omitempty" gorm:"foreignKey:DeliveryID"`
+	Endpoint       *WebhookEndpoint `json:"-" gorm:"foreignKey:EndpointID"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// WebhookAttempt One request made for a delivery
+type WebhookAttempt struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	DeliveryID uint      `json:"delivery_id" gorm:"not null;index"`
+	StatusCode int       `json:"status_code"` // 0 when no response was received
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Subscribed Whether the endpoint receives an event type
+func (w *WebhookEndpoint) Subscribed(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
@@ -31,6 +31,30 @@ func Migrate() error {
 		&models.Staff{},
 		&models.Service{},
 		&models.Reservation{},
+		&models.StaffBlock{},
+		&models.ReservationAudit{},
+		&models.Review{},
+		&models.FavoriteSalon{},
+		&models.FavoriteStaff{},
+		&models.SearchDocument{},
+		&models.Payment{},
+		&models.PaymentEvent{},
+		&models.Promotion{},
+		&models.PromotionRedemption{},
+		&models.PriceRule{},
+		&models.PointEntry{},
+		&models.PointRedemption{},
+		&models.GiftCard{},
+		&models.GiftCardTransaction{},
+		&models.NotificationPreference{},
+		&models.ReminderDelivery{},
+		&models.WebhookEndpoint{},
+		&models.OutboxEvent{},
+		&models.WebhookDelivery{},
+		&models.WebhookAttempt{},
+		&models.CalendarFeed{},
+		&models.StaffCalendarSource{},
+		&models.SlotHold{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
@@ -0,0 +1,198 @@
+// Package cache keeps computed responses (salon details and lists, availability) for a short time.
+//
+// Entries are grouped in namespaces that are invalidated as a whole. Each namespace has a
+// generation number that is part of its entry keys; invalidating the namespace moves the
+// generation on, so the old entries are never read again and simply expire. A value computed
+// while its namespace is invalidated is stored under the old generation and never served.
+// The same scheme works in Redis, shared by every server instance, and in process.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	entryPrefix      = "cache:entry:"
+	generationPrefix = "cache:gen:" // Generations never expire (use a Redis eviction policy that only evicts volatile keys)
+)
+
+// MemoryAddress Cache address selecting the in-process backend (single server instance only)
+const MemoryAddress = "memory"
+
+// Connection attempts to a configured Redis at startup, waiting redisRetryWait longer after each
+const (
+	redisConnectAttempts = 5
+	redisRetryWait       = time.Second
+)
+
+// Backend Key-value store holding the entries and namespace generations
+type Backend interface {
+	// Name Backend name reported with the statistics
+	Name() string
+	// Get Value of a key; false when it is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set Store a value, kept forever when ttl is 0
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Incr Atomically increment a counter (created at 0) and return the new value
+	Incr(ctx context.Context, key string) (int64, error)
+	// DeletePrefix Delete every key starting with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// Stats Lookups of one kind of entry
+type Stats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Errors   int64   `json:"errors"` // Backend failures; the value is computed as on a miss
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+type counters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// Store Namespaced cache over a backend, counting hits and misses per kind of entry
+type Store struct {
+	backend Backend
+
+	mu    sync.Mutex
+	kinds map[string]*counters
+}
+
+// NewStore Store over the backend
+func NewStore(backend Backend) *Store {
+	return &Store{backend: backend, kinds: make(map[string]*counters)}
+}
+
+// Open Store on the Redis server at address, or in process when address is empty or MemoryAddress.
+// A configured Redis that can't be reached is retried a few times and then reported: falling back to a
+// per-instance cache would keep invalidations from reaching the other instances.
+func Open(address string) (*Store, error) {
+	if address == "" || address == MemoryAddress {
+		return NewStore(NewMemory(DefaultMemoryEntries)), nil
+	}
+	if _, err := redisOptions(address); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		backend, err := NewRedis(address)
+		if err == nil {
+			return NewStore(backend), nil
+		}
+		if attempt == redisConnectAttempts {
+			return nil, fmt.Errorf("redis at %s: %w", address, err)
+		}
+		log.Printf("Redis unavailable at %s (attempt %d of %d): %v", address, attempt, redisConnectAttempts, err)
+		time.Sleep(time.Duration(attempt) * redisRetryWait)
+	}
+}
+
+// Backend Name of the backend
+func (s *Store) Backend() string {
+	return s.backend.Name()
+}
+
+// Invalidate Drop every entry of the namespaces
+func (s *Store) Invalidate(ctx context.Context, namespaces ...string) error {
+	for _, namespace := range namespaces {
+		if _, err := s.backend.Incr(ctx, generationPrefix+namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush Drop every entry (the generations are kept so no old entry can come back)
+func (s *Store) Flush(ctx context.Context) error {
+	return s.backend.DeletePrefix(ctx, entryPrefix)
+}
+
+// Stats Lookup counts by kind of entry since the server started
+func (s *Store) Stats() map[string]Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]Stats, len(s.kinds))
+	for kind, c := range s.kinds {
+		entry := Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Errors: c.errors.Load()}
+		if total := entry.Hits + entry.Misses; total > 0 {
+			entry.HitRatio = float64(entry.Hits) / float64(total)
+		}
+		stats[kind] = entry
+	}
+	return stats
+}
+
+// counters Counters of a kind of entry
+func (s *Store) counters(kind string) *counters {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.kinds[kind]
+	if !ok {
+		c = &counters{}
+		s.kinds[kind] = c
+	}
+	return c
+}
+
+// entryKey Backend key of an entry in the namespace's current generation
+func (s *Store) entryKey(ctx context.Context, namespace, key string) (string, error) {
+	generation := "0"
+	value, ok, err := s.backend.Get(ctx, generationPrefix+namespace)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		generation = string(value)
+	}
+	return entryPrefix + namespace + ":" + generation + ":" + key, nil
+}
+
+// Remember Cached value of the key in the namespace, computed and stored for ttl on a miss.
+// Cache failures never fail the lookup; errors from compute are returned and not cached.
+func Remember[T any](ctx context.Context, s *Store, kind, namespace, key string, ttl time.Duration, compute func() (T, error)) (T, error) {
+	c := s.counters(kind)
+
+	entryKey, err := s.entryKey(ctx, namespace, key)
+	if err != nil {
+		c.errors.Add(1)
+		c.misses.Add(1)
+		return compute()
+	}
+
+	data, ok, err := s.backend.Get(ctx, entryKey)
+	if err != nil {
+		c.errors.Add(1)
+	} else if ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			c.hits.Add(1)
+			return value, nil
+		}
+		c.errors.Add(1)
+	}
+	c.misses.Add(1)
+
+	value, err := compute()
+	if err != nil {
+		return value, err
+	}
+
+	if data, err = json.Marshal(value); err == nil {
+		err = s.backend.Set(ctx, entryKey, data, ttl)
+	}
+	if err != nil {
+		c.errors.Add(1)
+	}
+	return value, nil
+}
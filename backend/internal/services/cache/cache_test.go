@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var ctx = context.Background()
+
+func TestMemory(t *testing.T) {
+	m := NewMemory(DefaultMemoryEntries)
+
+	if _, ok, err := m.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("Get(missing) = %v, %v, want a miss", ok, err)
+	}
+
+	m.Set(ctx, "kept", []byte("a"), 0)
+	m.Set(ctx, "short", []byte("b"), 10*time.Millisecond)
+	if value, ok, _ := m.Get(ctx, "short"); !ok || string(value) != "b" {
+		t.Fatalf("Get(short) = %q, %v, want b", value, ok)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok, _ := m.Get(ctx, "short"); ok {
+		t.Error("Get(short) after its ttl hit, want a miss")
+	}
+	if value, ok, _ := m.Get(ctx, "kept"); !ok || string(value) != "a" {
+		t.Errorf("Get(kept) = %q, %v, want a kept without ttl", value, ok)
+	}
+}
+
+func TestMemoryIncr(t *testing.T) {
+	m := NewMemory(DefaultMemoryEntries)
+	for want := int64(1); want <= 3; want++ {
+		if n, err := m.Incr(ctx, "counter"); n != want || err != nil {
+			t.Fatalf("Incr() = %d, %v, want %d", n, err, want)
+		}
+	}
+
+	m.Set(ctx, "text", []byte("not a number"), 0)
+	if _, err := m.Incr(ctx, "text"); err == nil {
+		t.Error("Incr() of a non-numeric value succeeded, want an error")
+	}
+}
+
+func TestMemoryDeletePrefix(t *testing.T) {
+	m := NewMemory(DefaultMemoryEntries)
+	m.Set(ctx, entryPrefix+"salon:1", []byte("a"), time.Minute)
+	m.Set(ctx, entryPrefix+"salon:2", []byte("b"), time.Minute)
+	m.Set(ctx, generationPrefix+"salon", []byte("3"), 0)
+
+	m.DeletePrefix(ctx, entryPrefix)
+	for _, key := range []string{entryPrefix + "salon:1", entryPrefix + "salon:2"} {
+		if _, ok, _ := m.Get(ctx, key); ok {
+			t.Errorf("Get(%s) after DeletePrefix hit, want a miss", key)
+		}
+	}
+	if _, ok, _ := m.Get(ctx, generationPrefix+"salon"); !ok {
+		t.Error("DeletePrefix dropped a key outside the prefix")
+	}
+}
+
+func TestMemoryEviction(t *testing.T) {
+	m := NewMemory(3)
+	m.Set(ctx, "generation", []byte("1"), 0)
+	m.Set(ctx, "soon", []byte("a"), time.Minute)
+	m.Set(ctx, "later", []byte("b"), time.Hour)
+	m.Set(ctx, "new", []byte("c"), time.Hour)
+
+	// The entry closest to its expiry makes room; keys without expiry are never dropped
+	if _, ok, _ := m.Get(ctx, "soon"); ok {
+		t.Error("entry closest to expiry was kept, want it evicted")
+	}
+	for _, key := range []string{"generation", "later", "new"} {
+		if _, ok, _ := m.Get(ctx, key); !ok {
+			t.Errorf("Get(%s) missed after eviction", key)
+		}
+	}
+}
+
+// counter compute function counting its calls
+type counter struct {
+	calls int
+}
+
+func (c *counter) compute() (int, error) {
+	c.calls++
+	return c.calls, nil
+}
+
+func TestRemember(t *testing.T) {
+	s := NewStore(NewMemory(DefaultMemoryEntries))
+	c := &counter{}
+
+	for i := 0; i < 3; i++ {
+		value, err := Remember(ctx, s, "salon", "salon:1", "fields=all", time.Minute, c.compute)
+		if value != 1 || err != nil {
+			t.Fatalf("Remember() = %d, %v, want the first computed value", value, err)
+		}
+	}
+	if c.calls != 1 {
+		t.Errorf("compute called %d times, want 1", c.calls)
+	}
+
+	// Keys are separate within a namespace
+	if value, _ := Remember(ctx, s, "salon", "salon:1", "fields=summary", time.Minute, c.compute); value != 2 {
+		t.Errorf("Remember() of another key = %d, want a new value", value)
+	}
+
+	stats := s.Stats()["salon"]
+	if stats.Hits != 2 || stats.Misses != 2 || stats.HitRatio != 0.5 {
+		t.Errorf("Stats() = %+v, want 2 hits and 2 misses", stats)
+	}
+}
+
+func TestRememberComputeError(t *testing.T) {
+	s := NewStore(NewMemory(DefaultMemoryEntries))
+	failure := errors.New("database unavailable")
+
+	_, err := Remember(ctx, s, "salon", "salon:1", "", time.Minute, func() (int, error) { return 0, failure })
+	if !errors.Is(err, failure) {
+		t.Fatalf("Remember() error = %v, want %v", err, failure)
+	}
+
+	// Errors are not cached
+	value, err := Remember(ctx, s, "salon", "salon:1", "", time.Minute, func() (int, error) { return 7, nil })
+	if value != 7 || err != nil {
+		t.Errorf("Remember() after an error = %d, %v, want 7", value, err)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	s := NewStore(NewMemory(DefaultMemoryEntries))
+	salon, other := &counter{}, &counter{}
+
+	Remember(ctx, s, "salon", "salon:1", "", time.Minute, salon.compute)
+	Remember(ctx, s, "salon", "salon:2", "", time.Minute, other.compute)
+
+	if err := s.Invalidate(ctx, "salon:1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := Remember(ctx, s, "salon", "salon:1", "", time.Minute, salon.compute); value != 2 {
+		t.Errorf("Remember() after Invalidate = %d, want a recomputed value", value)
+	}
+	if value, _ := Remember(ctx, s, "salon", "salon:2", "", time.Minute, other.compute); value != 1 {
+		t.Errorf("Remember() of another namespace = %d, want the cached value", value)
+	}
+}
+
+func TestInvalidateDuringCompute(t *testing.T) {
+	s := NewStore(NewMemory(DefaultMemoryEntries))
+	calls := 0
+
+	// A value computed while its namespace is invalidated is stored under the old generation
+	Remember(ctx, s, "salon", "salon:1", "", time.Minute, func() (int, error) {
+		calls++
+		s.Invalidate(ctx, "salon:1")
+		return calls, nil
+	})
+
+	value, _ := Remember(ctx, s, "salon", "salon:1", "", time.Minute, func() (int, error) {
+		calls++
+		return calls, nil
+	})
+	if value != 2 {
+		t.Errorf("Remember() = %d, want the stale value never served", value)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	backend := NewMemory(DefaultMemoryEntries)
+	s := NewStore(backend)
+	c := &counter{}
+
+	Remember(ctx, s, "salon", "salon:1", "", time.Minute, c.compute)
+	s.Invalidate(ctx, "salon:1")
+	Remember(ctx, s, "salon", "salon:1", "", time.Minute, c.compute)
+
+	if err := s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := Remember(ctx, s, "salon", "salon:1", "", time.Minute, c.compute); value != 3 {
+		t.Errorf("Remember() after Flush = %d, want a recomputed value", value)
+	}
+
+	// Generations survive the flush so entries of older generations can't come back
+	if generation, ok, _ := backend.Get(ctx, generationPrefix+"salon:1"); !ok || string(generation) != "1" {
+		t.Errorf("generation after Flush = %q, %v, want 1", generation, ok)
+	}
+}
+
+// failingBackend Backend whose every operation fails
+type failingBackend struct{}
+
+var errBackend = errors.New("backend unavailable")
+
+func (failingBackend) Name() string { return "failing" }
+
+func (failingBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errBackend
+}
+
+func (failingBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errBackend
+}
+
+func (failingBackend) Incr(ctx context.Context, key string) (int64, error) { return 0, errBackend }
+
+func (failingBackend) DeletePrefix(ctx context.Context, prefix string) error { return errBackend }
+
+func TestRememberBackendFailure(t *testing.T) {
+	s := NewStore(failingBackend{})
+	c := &counter{}
+
+	for want := 1; want <= 2; want++ {
+		if value, err := Remember(ctx, s, "salon", "salon:1", "", time.Minute, c.compute); value != want || err != nil {
+			t.Fatalf("Remember() = %d, %v, want a computed value", value, err)
+		}
+	}
+
+	stats := s.Stats()["salon"]
+	if stats.Errors != 2 || stats.Misses != 2 || stats.Hits != 0 {
+		t.Errorf("Stats() = %+v, want 2 errors counted as misses", stats)
+	}
+	if err := s.Invalidate(ctx, "salon:1"); !errors.Is(err, errBackend) {
+		t.Errorf("Invalidate() error = %v, want %v", err, errBackend)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for _, address := range []string{"", MemoryAddress} {
+		s, err := Open(address)
+		if err != nil || s.Backend() != "memory" {
+			t.Errorf("Open(%q) = %v, %v, want the in-process backend", address, s, err)
+		}
+	}
+
+	if _, err := Open("redis://localhost:6379/not-a-database"); err == nil {
+		t.Error("Open() of an invalid URL succeeded, want an error")
+	}
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryEntries Entries kept in process before the oldest-expiring ones are dropped
+const DefaultMemoryEntries = 10000
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // Zero when the entry never expires
+}
+
+// expired Check whether the entry is past its expiry
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Memory In-process backend for a single server instance and tests
+type Memory struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	maxEntries int
+}
+
+// NewMemory In-process backend holding at most maxEntries keys
+func NewMemory(maxEntries int) *Memory {
+	return &Memory{entries: make(map[string]memoryEntry), maxEntries: maxEntries}
+}
+
+// Name Backend name
+func (m *Memory) Name() string {
+	return "memory"
+}
+
+// Get Value of a key
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if entry.expired(time.Now()) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// Set Store a value
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	if _, ok := m.entries[key]; !ok {
+		m.makeRoom()
+	}
+	m.entries[key] = entry
+	return nil
+}
+
+// Incr Increment a counter
+func (m *Memory) Incr(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	entry, ok := m.entries[key]
+	if ok && !entry.expired(time.Now()) {
+		var err error
+		if n, err = strconv.ParseInt(string(entry.value), 10, 64); err != nil {
+			return 0, err
+		}
+	} else {
+		entry = memoryEntry{}
+		m.makeRoom()
+	}
+	n++
+	m.entries[key] = memoryEntry{value: []byte(strconv.FormatInt(n, 10)), expiresAt: entry.expiresAt}
+	return n, nil
+}
+
+// DeletePrefix Delete the keys starting with prefix
+func (m *Memory) DeletePrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+// makeRoom Drop expired entries when full, then the expiring entry closest to its expiry.
+// Keys without expiry (generations) are never dropped.
+func (m *Memory) makeRoom() {
+	if m.maxEntries <= 0 || len(m.entries) < m.maxEntries {
+		return
+	}
+
+	now := time.Now()
+	for key, entry := range m.entries {
+		if entry.expired(now) {
+			delete(m.entries, key)
+		}
+	}
+	if len(m.entries) < m.maxEntries {
+		return
+	}
+
+	victim := ""
+	var victimExpiry time.Time
+	for key, entry := range m.entries {
+		if entry.expiresAt.IsZero() {
+			continue
+		}
+		if victim == "" || entry.expiresAt.Before(victimExpiry) {
+			victim, victimExpiry = key, entry.expiresAt
+		}
+	}
+	if victim != "" {
+		delete(m.entries, victim)
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Timeouts keeping a slow Redis from holding up requests (the value is computed instead)
+const (
+	redisDialTimeout = 2 * time.Second
+	redisIOTimeout   = 500 * time.Millisecond
+)
+
+// redisDeleteBatch Keys scanned and deleted per round trip by DeletePrefix
+const redisDeleteBatch = 500
+
+// Redis Backend shared by every server instance
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis Connect to Redis at a redis:// URL or a host:port address
+func NewRedis(address string) (*Redis, error) {
+	options, err := redisOptions(address)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisDialTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &Redis{client: client}, nil
+}
+
+// redisOptions Client options for a redis:// URL or a host:port address
+func redisOptions(address string) (*redis.Options, error) {
+	options := &redis.Options{Addr: address}
+	if strings.Contains(address, "://") {
+		var err error
+		if options, err = redis.ParseURL(address); err != nil {
+			return nil, err
+		}
+	}
+	if options.DialTimeout == 0 {
+		options.DialTimeout = redisDialTimeout
+	}
+	if options.ReadTimeout == 0 {
+		options.ReadTimeout = redisIOTimeout
+	}
+	if options.WriteTimeout == 0 {
+		options.WriteTimeout = redisIOTimeout
+	}
+	return options, nil
+}
+
+// Name Backend name
+func (r *Redis) Name() string {
+	return "redis"
+}
+
+// Get Value of a key
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set Store a value
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Incr Increment a counter
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// DeletePrefix Delete the keys starting with prefix, scanning in batches so Redis isn't blocked
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) error {
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, prefix+"*", redisDeleteBatch).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := r.client.Unlink(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,58 @@
+// Package giftcard generates and normalizes gift card codes.
+//
+// Codes are 16 characters from an alphabet without look-alike characters (0/O, 1/I/L),
+// shown in groups of four: "ABCD-EFGH-JKMN-PQRS". Input is accepted in any case, with or
+// without separators.
+package giftcard
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Transaction types
+const (
+	TxIssue  = "issue"
+	TxRedeem = "redeem" // Part of a reservation paid with the card
+	TxRefund = "refund" // Redeemed amount given back when the reservation is cancelled
+)
+
+const (
+	alphabet   = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	codeLength = 16
+	groupSize  = 4
+)
+
+// NewCode Random gift card code
+func NewCode() (string, error) {
+	raw := make([]byte, codeLength)
+	limit := big.NewInt(int64(len(alphabet)))
+	for i := range raw {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		raw[i] = alphabet[n.Int64()]
+	}
+	return format(string(raw)), nil
+}
+
+// NormalizeCode Canonical form of a code typed by a customer
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return format(code)
+}
+
+// format Insert separators between groups
+func format(raw string) string {
+	var b strings.Builder
+	for i, r := range raw {
+		if i > 0 && i%groupSize == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
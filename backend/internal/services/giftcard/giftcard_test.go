@@ -0,0 +1,49 @@
+package giftcard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"canonical", "ABCD-EFGH-JKMN-PQRS", "ABCD-EFGH-JKMN-PQRS"},
+		{"lower case", "abcd-efgh-jkmn-pqrs", "ABCD-EFGH-JKMN-PQRS"},
+		{"without separators", "ABCDEFGHJKMNPQRS", "ABCD-EFGH-JKMN-PQRS"},
+		{"spaces as separators", "ABCD EFGH JKMN PQRS", "ABCD-EFGH-JKMN-PQRS"},
+		{"misplaced separators", "AB-CDEF-GHJKMN-PQRS", "ABCD-EFGH-JKMN-PQRS"},
+		{"short code", "abc", "ABC"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeCode(tt.code); got != tt.want {
+				t.Errorf("NormalizeCode(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCode(t *testing.T) {
+	code, err := NewCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(code) != codeLength+codeLength/groupSize-1 {
+		t.Fatalf("NewCode() = %q, want %d characters in groups of %d", code, codeLength, groupSize)
+	}
+	if got := NormalizeCode(code); got != code {
+		t.Errorf("NormalizeCode(%q) = %q, want the code unchanged", code, got)
+	}
+	for _, r := range strings.ReplaceAll(code, "-", "") {
+		if !strings.ContainsRune(alphabet, r) {
+			t.Errorf("NewCode() = %q, contains %q outside the alphabet", code, r)
+		}
+	}
+}
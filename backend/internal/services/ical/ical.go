@@ -0,0 +1,197 @@
+// Package ical writes iCalendar (RFC 5545) files for reservation downloads and feeds.
+//
+// Events keep a stable UID across updates and carry a SEQUENCE that increases with every
+// change, so calendar clients replace the event they already have. Cancelled events stay in
+// feeds with STATUS:CANCELLED. Times are written in the calendar's zone with a VTIMEZONE
+// when the zone has a fixed offset (e.g. Asia/Tokyo), and in UTC otherwise.
+package ical
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event statuses
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// ContentType Media type of iCalendar files
+const ContentType = "text/calendar; charset=utf-8"
+
+// maxLineOctets Lines longer than this are folded
+const maxLineOctets = 75
+
+// Calendar A set of events
+type Calendar struct {
+	ProdID          string
+	Name            string         // Shown by clients subscribing to a feed
+	Location        *time.Location // Zone of the event times
+	RefreshInterval time.Duration  // Suggested feed polling interval; 0 for none
+	Events          []Event
+}
+
+// Event One appointment
+type Event struct {
+	UID          string
+	Sequence     int
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Status       string // StatusConfirmed or StatusCancelled
+	Created      time.Time
+	LastModified time.Time
+}
+
+// String Render the calendar with CRLF line endings
+func (c Calendar) String() string {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + c.ProdID)
+	w.line("CALSCALE:GREGORIAN")
+	w.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		interval := duration(c.RefreshInterval)
+		w.line("REFRESH-INTERVAL;VALUE=DURATION:" + interval)
+		w.line("X-PUBLISHED-TTL:" + interval)
+	}
+
+	tzid := ""
+	if c.Location != nil && fixedOffset(c.Location) {
+		tzid = c.Location.String()
+		w.line("X-WR-TIMEZONE:" + tzid)
+		w.timezone(c.Location)
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, event := range c.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + event.UID)
+		w.line("DTSTAMP:" + stamp)
+		w.line(dateTime("DTSTART", event.Start, c.Location, tzid))
+		w.line(dateTime("DTEND", event.End, c.Location, tzid))
+		w.line(fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		w.line("SUMMARY:" + escape(event.Summary))
+		if event.Description != "" {
+			w.line("DESCRIPTION:" + escape(event.Description))
+		}
+		if event.Location != "" {
+			w.line("LOCATION:" + escape(event.Location))
+		}
+		if event.URL != "" {
+			w.line("URL:" + event.URL)
+		}
+		status := event.Status
+		if status == "" {
+			status = StatusConfirmed
+		}
+		w.line("STATUS:" + status)
+		if !event.Created.IsZero() {
+			w.line("CREATED:" + event.Created.UTC().Format("20060102T150405Z"))
+		}
+		if !event.LastModified.IsZero() {
+			w.line("LAST-MODIFIED:" + event.LastModified.UTC().Format("20060102T150405Z"))
+		}
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+	return w.String()
+}
+
+// writer Builds content lines
+type writer struct {
+	strings.Builder
+}
+
+// line Write a content line, folded to 75 octets without splitting UTF-8 characters
+func (w *writer) line(content string) {
+	octets := 0
+	for _, r := range content {
+		size := len(string(r))
+		if octets+size > maxLineOctets {
+			w.WriteString("\r\n ")
+			octets = 1
+		}
+		w.WriteRune(r)
+		octets += size
+	}
+	w.WriteString("\r\n")
+}
+
+// timezone Write a VTIMEZONE for a fixed-offset zone
+func (w *writer) timezone(loc *time.Location) {
+	name, offset := time.Date(2000, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	w.line("BEGIN:VTIMEZONE")
+	w.line("TZID:" + loc.String())
+	w.line("BEGIN:STANDARD")
+	w.line("DTSTART:19700101T000000")
+	w.line("TZOFFSETFROM:" + utcOffset(offset))
+	w.line("TZOFFSETTO:" + utcOffset(offset))
+	w.line("TZNAME:" + name)
+	w.line("END:STANDARD")
+	w.line("END:VTIMEZONE")
+}
+
+// fixedOffset Whether a zone has the same offset all year
+func fixedOffset(loc *time.Location) bool {
+	_, winter := time.Date(2000, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, summer := time.Date(2000, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	return winter == summer
+}
+
+// dateTime Date-time property in the zone, or in UTC without one
+func dateTime(name string, t time.Time, loc *time.Location, tzid string) string {
+	if tzid == "" {
+		return name + ":" + t.UTC().Format("20060102T150405Z")
+	}
+	return name + ";TZID=" + tzid + ":" + t.In(loc).Format("20060102T150405")
+}
+
+// utcOffset Offset in seconds as "+hhmm"
+func utcOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// duration Duration value ("PT1H30M")
+func duration(d time.Duration) string {
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	value := "PT"
+	if hours > 0 {
+		value += fmt.Sprintf("%dH", hours)
+	}
+	if minutes > 0 || hours == 0 {
+		value += fmt.Sprintf("%dM", minutes)
+	}
+	return value
+}
+
+// NewFeedToken Random token identifying a private subscription feed
+func NewFeedToken() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// escape Escape a TEXT value
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
@@ -0,0 +1,142 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var tokyo = time.FixedZone("Asia/Tokyo", 9*60*60)
+
+// unfold Content lines of a rendered calendar with folded lines joined back
+func unfold(t *testing.T, rendered string) []string {
+	t.Helper()
+	if !strings.HasSuffix(rendered, "\r\n") {
+		t.Fatalf("calendar does not end with CRLF: %q", rendered)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(rendered, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line longer than %d octets: %q", maxLineOctets, line)
+		}
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(rendered, "\r\n ", ""), "\r\n"), "\r\n")
+}
+
+// hasLine Whether a content line is in the calendar
+func hasLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCalendarString(t *testing.T) {
+	start := time.Date(2024, 3, 9, 14, 30, 0, 0, tokyo)
+	calendar := Calendar{
+		ProdID:          "-//reservation-platform-sample//Test//EN",
+		Name:            "Salon A",
+		Location:        tokyo,
+		RefreshInterval: 90 * time.Minute,
+		Events: []Event{{
+			UID:         "reservation-42@reservation-platform-sample",
+			Sequence:    3,
+			Start:       start,
+			End:         start.Add(time.Hour),
+			Summary:     "Cut, color; Sato",
+			Description: "Line one\nLine two",
+			Status:      StatusCancelled,
+		}},
+	}
+	lines := unfold(t, calendar.String())
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR",
+		"X-WR-CALNAME:Salon A",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H30M",
+		"X-WR-TIMEZONE:Asia/Tokyo",
+		"TZID:Asia/Tokyo",
+		"TZOFFSETTO:+0900",
+		"UID:reservation-42@reservation-platform-sample",
+		"SEQUENCE:3",
+		"DTSTART;TZID=Asia/Tokyo:20240309T143000",
+		"DTEND;TZID=Asia/Tokyo:20240309T153000",
+		`SUMMARY:Cut\, color\; Sato`,
+		`DESCRIPTION:Line one\nLine two`,
+		"STATUS:CANCELLED",
+		"END:VCALENDAR",
+	} {
+		if !hasLine(lines, want) {
+			t.Errorf("calendar has no line %q:\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+}
+
+func TestCalendarStringTimes(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	start := time.Date(2024, 3, 9, 14, 30, 0, 0, tokyo)
+	tests := []struct {
+		name      string
+		location  *time.Location
+		wantStart string
+		wantTZ    bool
+	}{
+		{"fixed offset zone with VTIMEZONE", tokyo, "DTSTART;TZID=Asia/Tokyo:20240309T143000", true},
+		{"zone with daylight saving in UTC", newYork, "DTSTART:20240309T053000Z", false},
+		{"no zone in UTC", nil, "DTSTART:20240309T053000Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := Calendar{ProdID: "-//test//EN", Location: tt.location, Events: []Event{{UID: "1", Start: start, End: start.Add(time.Hour)}}}
+			lines := unfold(t, calendar.String())
+			if !hasLine(lines, tt.wantStart) {
+				t.Errorf("calendar has no line %q", tt.wantStart)
+			}
+			if hasLine(lines, "BEGIN:VTIMEZONE") != tt.wantTZ {
+				t.Errorf("VTIMEZONE written = %v, want %v", !tt.wantTZ, tt.wantTZ)
+			}
+			// Events without a status are confirmed and start at sequence 0
+			if !hasLine(lines, "STATUS:CONFIRMED") || !hasLine(lines, "SEQUENCE:0") {
+				t.Errorf("calendar has no default status and sequence:\n%s", strings.Join(lines, "\n"))
+			}
+		})
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	summary := strings.Repeat("カット", 20)
+	calendar := Calendar{ProdID: "-//test//EN", Events: []Event{{UID: "1", Summary: summary}}}
+	rendered := calendar.String()
+
+	// unfold fails the test on lines over 75 octets
+	if !hasLine(unfold(t, rendered), "SUMMARY:"+summary) {
+		t.Errorf("folded summary does not unfold to the original:\n%s", rendered)
+	}
+	if !strings.Contains(rendered, "\r\n ") {
+		t.Error("long summary was not folded")
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{time.Hour, "PT1H"},
+		{90 * time.Minute, "PT1H30M"},
+		{15 * time.Minute, "PT15M"},
+		{0, "PT0M"},
+	}
+
+	for _, tt := range tests {
+		if got := duration(tt.d); got != tt.want {
+			t.Errorf("duration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
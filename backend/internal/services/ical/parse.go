@@ -0,0 +1,510 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods Stops expanding a recurring event after this many periods (e.g. days of a daily rule)
+const maxPeriods = 20000
+
+var (
+	ErrNotCalendar     = errors.New("not an iCalendar file")
+	errInvalidDuration = errors.New("invalid duration")
+)
+
+// Busy Time taken by an event of an imported calendar
+type Busy struct {
+	UID    string // Empty for free/busy periods
+	Start  time.Time
+	End    time.Time
+	AllDay bool
+}
+
+// ValidSource Whether a calendar source URL can be imported (http, https, webcal or a local file)
+func ValidSource(source string) bool {
+	u, err := url.Parse(source)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "http", "https", "webcal":
+		return u.Host != ""
+	case "file":
+		return u.Host+u.Path != ""
+	default:
+		return false
+	}
+}
+
+// ParseBusy Busy times of a calendar overlapping [from, to), sorted by start.
+//
+// Events count as busy unless they are transparent or cancelled; VFREEBUSY periods count unless
+// FBTYPE=FREE. Dates and floating times are read in loc, as are TZIDs Go does not know.
+// Recurring events are expanded for RRULEs with FREQ DAILY, WEEKLY, MONTHLY or YEARLY, INTERVAL,
+// COUNT, UNTIL and (weekly) BYDAY, minus EXDATEs and occurrences overridden by RECURRENCE-ID.
+// Events with other rules (e.g. BYDAY=2TU, BYMONTHDAY, BYSETPOS) can't be expanded: only their
+// first occurrence is busy and their UIDs are returned in unsupported, so the caller can tell
+// that later occurrences are missing. Malformed events are skipped.
+func ParseBusy(r io.Reader, loc *time.Location, from, to time.Time) (busy []Busy, unsupported []string, err error) {
+	properties, err := readProperties(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(properties) == 0 || properties[0].name != "BEGIN" || !strings.EqualFold(properties[0].value, "VCALENDAR") {
+		return nil, nil, ErrNotCalendar
+	}
+
+	var events []*vevent
+	var current *vevent
+	var components []string
+	for _, p := range properties {
+		switch p.name {
+		case "BEGIN":
+			components = append(components, strings.ToUpper(p.value))
+			if strings.EqualFold(p.value, "VEVENT") {
+				current = &vevent{}
+			}
+			continue
+		case "END":
+			if len(components) > 0 {
+				components = components[:len(components)-1]
+			}
+			if strings.EqualFold(p.value, "VEVENT") && current != nil {
+				events = append(events, current)
+				current = nil
+			}
+			continue
+		}
+
+		// Properties of nested components (e.g. VALARM) don't describe the event
+		component := ""
+		if len(components) > 0 {
+			component = components[len(components)-1]
+		}
+		switch {
+		case component == "VEVENT" && current != nil:
+			current.set(p, loc)
+		case component == "VFREEBUSY" && p.name == "FREEBUSY":
+			busy = append(busy, freeBusyPeriods(p, from, to)...)
+		}
+	}
+
+	// Occurrences replaced by an override are not expanded from the master event
+	overridden := make(map[string]bool)
+	for _, event := range events {
+		if event.recurrenceID != nil {
+			overridden[occurrenceKey(event.uid, *event.recurrenceID)] = true
+		}
+	}
+
+	for _, event := range events {
+		if event.err != nil || event.start.IsZero() || event.free {
+			continue
+		}
+		if event.rrule != "" {
+			if _, ok := parseRule(event.rrule, event.start.Location()); !ok {
+				unsupported = append(unsupported, event.uid)
+			}
+		}
+		busy = append(busy, event.occurrences(from, to, overridden)...)
+	}
+
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+	return busy, unsupported, nil
+}
+
+// property One content line
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// readProperties Unfold and split the content lines
+func readProperties(r io.Reader) ([]property, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	properties := make([]property, 0, len(lines))
+	for _, line := range lines {
+		properties = append(properties, parseProperty(strings.TrimPrefix(line, "\ufeff")))
+	}
+	return properties, nil
+}
+
+// parseProperty Split "NAME;PARAM=value:VALUE" (colons inside quoted parameters don't end the name)
+func parseProperty(line string) property {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{name: strings.ToUpper(line)}
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	p := property{name: strings.ToUpper(parts[0]), params: make(map[string]string, len(parts)-1), value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return p
+}
+
+// vevent Event properties relevant to busy times
+type vevent struct {
+	uid          string
+	start        time.Time
+	end          time.Time
+	allDay       bool
+	duration     *time.Duration
+	rrule        string
+	exdates      []time.Time
+	recurrenceID *time.Time
+	free         bool
+	err          error // First malformed property
+}
+
+// set Apply a property to the event
+func (e *vevent) set(p property, loc *time.Location) {
+	var err error
+	switch p.name {
+	case "UID":
+		e.uid = p.value
+	case "DTSTART":
+		e.start, e.allDay, err = parseTime(p.value, p.params, loc)
+	case "DTEND":
+		e.end, _, err = parseTime(p.value, p.params, loc)
+	case "DURATION":
+		var d time.Duration
+		d, err = parseDuration(p.value)
+		e.duration = &d
+	case "RRULE":
+		e.rrule = p.value
+	case "EXDATE":
+		for _, value := range strings.Split(p.value, ",") {
+			var t time.Time
+			if t, _, err = parseTime(value, p.params, loc); err != nil {
+				break
+			}
+			e.exdates = append(e.exdates, t)
+		}
+	case "RECURRENCE-ID":
+		var t time.Time
+		t, _, err = parseTime(p.value, p.params, loc)
+		e.recurrenceID = &t
+	case "TRANSP":
+		e.free = e.free || strings.EqualFold(p.value, "TRANSPARENT")
+	case "STATUS":
+		e.free = e.free || strings.EqualFold(p.value, "CANCELLED")
+	}
+	if err != nil && e.err == nil {
+		e.err = fmt.Errorf("%s: %w", p.name, err)
+	}
+}
+
+// length Duration of each occurrence
+func (e *vevent) length() time.Duration {
+	switch {
+	case !e.end.IsZero():
+		return e.end.Sub(e.start)
+	case e.duration != nil:
+		return *e.duration
+	case e.allDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// occurrences Busy occurrences overlapping [from, to)
+func (e *vevent) occurrences(from, to time.Time, overridden map[string]bool) []Busy {
+	length := e.length()
+	if length <= 0 {
+		return nil
+	}
+
+	var busy []Busy
+	add := func(start time.Time) {
+		end := start.Add(length)
+		if start.Before(to) && end.After(from) {
+			busy = append(busy, Busy{UID: e.uid, Start: start, End: end, AllDay: e.allDay})
+		}
+	}
+
+	rule, ok := parseRule(e.rrule, e.start.Location())
+	if e.rrule == "" || !ok {
+		add(e.start)
+		return busy
+	}
+
+	excluded := make(map[int64]bool, len(e.exdates))
+	for _, exdate := range e.exdates {
+		excluded[exdate.Unix()] = true
+	}
+
+	generated := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, start := range rule.period(e.start, period) {
+			if rule.until != nil && start.After(*rule.until) {
+				return busy
+			}
+			generated++
+			if rule.count > 0 && generated > rule.count {
+				return busy
+			}
+			if !start.Before(to) {
+				return busy
+			}
+			if excluded[start.Unix()] || overridden[occurrenceKey(e.uid, start)] {
+				continue
+			}
+			add(start)
+		}
+	}
+	return busy
+}
+
+// occurrenceKey Identifies one occurrence of a recurring event
+func occurrenceKey(uid string, start time.Time) string {
+	return uid + "@" + strconv.FormatInt(start.Unix(), 10)
+}
+
+// rule Supported subset of an RRULE
+type rule struct {
+	freq     string
+	interval int
+	count    int
+	until    *time.Time
+	weekdays []time.Weekday // Weekly BYDAY, Monday first
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// parseRule Parse an RRULE; ok is false for rules using unsupported parts
+func parseRule(value string, loc *time.Location) (rule, bool) {
+	r := rule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return r, false
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return r, false
+			}
+			r.count = n
+		case "UNTIL":
+			until, allDay, err := parseTime(val, nil, loc)
+			if err != nil {
+				return r, false
+			}
+			// A date includes the whole day
+			if allDay {
+				until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+			r.until = &until
+		case "BYDAY":
+			for _, code := range strings.Split(strings.ToUpper(val), ",") {
+				weekday, ok := weekdayCodes[code]
+				if !ok {
+					return r, false // e.g. "2TU" (second Tuesday)
+				}
+				r.weekdays = append(r.weekdays, weekday)
+			}
+		case "WKST":
+		default:
+			return r, false
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "MONTHLY", "YEARLY":
+		return r, len(r.weekdays) == 0
+	case "WEEKLY":
+		sort.Slice(r.weekdays, func(i, j int) bool { return mondayFirst(r.weekdays[i]) < mondayFirst(r.weekdays[j]) })
+		return r, true
+	default:
+		return r, false
+	}
+}
+
+// period Occurrence starts in the n-th period of the rule, in order, never before the first start
+func (r rule) period(first time.Time, n int) []time.Time {
+	step := n * r.interval
+	switch r.freq {
+	case "DAILY":
+		return []time.Time{first.AddDate(0, 0, step)}
+	case "WEEKLY":
+		if len(r.weekdays) == 0 {
+			return []time.Time{first.AddDate(0, 0, 7*step)}
+		}
+		monday := first.AddDate(0, 0, 7*step-mondayFirst(first.Weekday()))
+		var starts []time.Time
+		for _, weekday := range r.weekdays {
+			start := monday.AddDate(0, 0, mondayFirst(weekday))
+			if !start.Before(first) {
+				starts = append(starts, start)
+			}
+		}
+		return starts
+	case "MONTHLY", "YEARLY":
+		start := first.AddDate(0, step, 0)
+		if r.freq == "YEARLY" {
+			start = first.AddDate(step, 0, 0)
+		}
+		// The 31st or 29 February don't occur in every month or year
+		if start.Day() != first.Day() {
+			return nil
+		}
+		return []time.Time{start}
+	}
+	return nil
+}
+
+// mondayFirst Days since Monday
+func mondayFirst(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+// freeBusyPeriods Busy periods of a FREEBUSY property overlapping [from, to)
+func freeBusyPeriods(p property, from, to time.Time) []Busy {
+	if strings.EqualFold(p.params["FBTYPE"], "FREE") {
+		return nil
+	}
+
+	var busy []Busy
+	for _, period := range strings.Split(p.value, ",") {
+		startValue, endValue, ok := strings.Cut(period, "/")
+		if !ok {
+			continue
+		}
+		start, err := time.Parse("20060102T150405Z", startValue)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("20060102T150405Z", endValue)
+		if err != nil {
+			d, err := parseDuration(endValue)
+			if err != nil {
+				continue
+			}
+			end = start.Add(d)
+		}
+		if start.Before(to) && end.After(from) && end.After(start) {
+			busy = append(busy, Busy{Start: start, End: end})
+		}
+	}
+	return busy
+}
+
+// parseTime Parse a DATE or DATE-TIME value; allDay is true for dates
+func parseTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	zone := loc
+	if tzid := params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			zone = tz
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, zone)
+	return t, false, err
+}
+
+// parseDuration Parse a DURATION value ("PT1H30M", "P1D", "-P1W")
+func parseDuration(value string) (time.Duration, error) {
+	sign := time.Duration(1)
+	if strings.HasPrefix(value, "-") {
+		sign = -1
+	}
+	value = strings.TrimLeft(value, "+-")
+	if !strings.HasPrefix(value, "P") {
+		return 0, errInvalidDuration
+	}
+
+	var d time.Duration
+	inTime := false
+	digits := ""
+	for _, r := range value[1:] {
+		if r >= '0' && r <= '9' {
+			digits += string(r)
+			continue
+		}
+		if r == 'T' {
+			inTime = true
+			continue
+		}
+
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, errInvalidDuration
+		}
+		digits = ""
+		switch {
+		case r == 'W' && !inTime:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, errInvalidDuration
+		}
+	}
+	if digits != "" {
+		return 0, errInvalidDuration
+	}
+	return sign * d, nil
+}
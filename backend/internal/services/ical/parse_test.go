@@ -0,0 +1,201 @@
+package ical
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "time/tzdata" // TZIDs in the sample calendars resolve the same everywhere
+)
+
+// calendarDir Sample calendars
+const calendarDir = "testdata"
+
+// busyString Busy time in Tokyo as "01-02 15:04/01-02 15:04", marked when all day
+func busyString(b Busy) string {
+	s := b.Start.In(tokyo).Format("01-02 15:04") + "/" + b.End.In(tokyo).Format("01-02 15:04")
+	if b.AllDay {
+		s += " all day"
+	}
+	return s
+}
+
+func TestParseBusyCalendars(t *testing.T) {
+	january := time.Date(2026, 1, 1, 0, 0, 0, 0, tokyo)
+	april := time.Date(2026, 4, 1, 0, 0, 0, 0, tokyo)
+
+	tests := []struct {
+		file            string
+		from, to        time.Time
+		want            []string
+		wantUnsupported []string
+	}{
+		{
+			file: "example.ics",
+			from: time.Date(2026, 1, 5, 0, 0, 0, 0, tokyo),
+			to:   time.Date(2026, 1, 12, 0, 0, 0, 0, tokyo),
+			// Weekly on Tuesdays and Fridays; the transparent lunch is free
+			want: []string{"01-06 13:00/01-06 17:00", "01-09 13:00/01-09 17:00"},
+		},
+		{
+			file: "tzid.ics",
+			// Unknown zones and floating times are read in the salon's zone
+			want: []string{
+				"03-03 10:00/03-03 11:00",
+				"03-04 10:00/03-04 10:30",
+				"03-05 10:00/03-05 11:00",
+				"03-05 23:00/03-06 00:00",
+				"03-10 22:00/03-10 23:00",
+			},
+		},
+		{
+			file: "all-day.ics",
+			want: []string{"01-20 00:00/01-22 00:00 all day", "01-30 00:00/01-31 00:00 all day"},
+		},
+		{
+			file: "exdate.ics",
+			want: []string{"01-05 10:00/01-05 11:00", "01-06 10:00/01-06 11:00", "01-08 10:00/01-08 11:00"},
+		},
+		{
+			file: "recurrence-id.ics",
+			// The second occurrence is moved and the third cancelled
+			want: []string{"01-05 10:00/01-05 11:00", "01-13 15:00/01-13 16:00"},
+		},
+		{
+			file: "count-until.ics",
+			want: []string{
+				"01-31 10:00/01-31 11:00",
+				"02-02 09:00/02-02 10:00",
+				"02-02 12:00/02-02 13:00",
+				"02-03 09:00/02-03 10:00",
+				"02-04 09:00/02-04 10:00",
+				"02-04 18:00/02-04 19:00",
+				"02-09 12:00/02-09 13:00",
+				"02-11 18:00/02-11 19:00",
+				"02-18 18:00/02-18 19:00",
+				"03-31 10:00/03-31 11:00",
+			},
+		},
+		{
+			file: "weekly-byday.ics",
+			// The Monday before the first occurrence is not part of the series
+			want: []string{
+				"01-07 13:00/01-07 14:00",
+				"01-19 13:00/01-19 14:00",
+				"01-21 13:00/01-21 14:00",
+				"02-02 13:00/02-02 14:00",
+				"02-04 13:00/02-04 14:00",
+			},
+		},
+		{
+			file: "freebusy.ics",
+			want: []string{"01-05 10:00/01-05 12:00", "01-06 10:00/01-06 11:30", "01-08 10:00/01-08 11:00"},
+		},
+		{
+			file:            "unsupported-rule.ics",
+			want:            []string{"01-13 10:00/01-13 11:00", "01-15 09:00/01-15 09:30", "01-30 17:00/01-30 18:00"},
+			wantUnsupported: []string{"second-tuesday@example.com", "pay-day@example.com", "last-friday@example.com"},
+		},
+	}
+
+	// Every sample calendar is covered
+	files, err := filepath.Glob(filepath.Join(calendarDir, "*.ics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tested := make(map[string]bool, len(tests))
+	for _, tt := range tests {
+		tested[tt.file] = true
+	}
+	for _, file := range files {
+		if !tested[filepath.Base(file)] {
+			t.Errorf("%s has no test case", filepath.Base(file))
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			from, to := tt.from, tt.to
+			if from.IsZero() {
+				from, to = january, april
+			}
+
+			f, err := os.Open(filepath.Join(calendarDir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			busy, unsupported, err := ParseBusy(f, tokyo, from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, len(busy))
+			for i, b := range busy {
+				got[i] = busyString(b)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("busy times:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if strings.Join(unsupported, ",") != strings.Join(tt.wantUnsupported, ",") {
+				t.Errorf("unsupported = %q, want %q", unsupported, tt.wantUnsupported)
+			}
+		})
+	}
+}
+
+func TestParseBusyNotCalendar(t *testing.T) {
+	for _, input := range []string{"", "<html><body>Sign in</body></html>", "BEGIN:VCARD\r\nEND:VCARD\r\n"} {
+		if _, _, err := ParseBusy(strings.NewReader(input), tokyo, time.Time{}, time.Now()); err != ErrNotCalendar {
+			t.Errorf("ParseBusy(%q) error = %v, want %v", input, err, ErrNotCalendar)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"PT1H30M", 90 * time.Minute, false},
+		{"P1D", 24 * time.Hour, false},
+		{"P1W", 7 * 24 * time.Hour, false},
+		{"P1DT2H", 26 * time.Hour, false},
+		{"-PT15M", -15 * time.Minute, false},
+		{"PT45S", 45 * time.Second, false},
+		{"1H", 0, true},
+		{"PT1", 0, true},
+		{"P1H", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDuration(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v, want %v (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestValidSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"https://calendar.example.com/basic.ics", true},
+		{"webcal://calendar.example.com/basic.ics", true},
+		{"file://example.ics", true},
+		{"ftp://calendar.example.com/basic.ics", false},
+		{"https://", false},
+		{"not a url", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidSource(tt.source); got != tt.want {
+			t.Errorf("ValidSource(%q) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
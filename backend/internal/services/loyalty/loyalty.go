@@ -0,0 +1,47 @@
+// Package loyalty defines the loyalty points rules.
+//
+// Points are kept in a per-user ledger of signed entries. Credits (earned or refunded points)
+// expire after the program's period and are spent oldest expiry first.
+package loyalty
+
+import (
+	"errors"
+	"time"
+)
+
+// Ledger entry types
+const (
+	EntryEarn   = "earn"   // Credit for a completed reservation
+	EntryRedeem = "redeem" // Debit for a discount at booking time
+	EntryRefund = "refund" // Credit giving back the points of a cancelled reservation
+	EntryExpire = "expire" // Debit of the unspent part of an expired credit
+)
+
+var ErrInsufficientPoints = errors.New("not enough points")
+
+// Program Earning, redemption and expiry rules
+type Program struct {
+	EarnPercent int // Points earned per 100 yen paid
+	YenPerPoint int // Discount given by one point
+	ExpiryDays  int // Days a credit stays spendable
+}
+
+// Earned Points earned for an amount paid (rounded down)
+func (p Program) Earned(paid int) int {
+	return max(paid, 0) * p.EarnPercent / 100
+}
+
+// Value Discount in yen given by points
+func (p Program) Value(points int) int {
+	return points * p.YenPerPoint
+}
+
+// Redeemable Points that can be spent on a price, given a requested amount
+func (p Program) Redeemable(requested, price int) int {
+	return max(min(requested, price/p.YenPerPoint), 0)
+}
+
+// ExpiresAt Expiry of a credit given at a time
+func (p Program) ExpiresAt(credited time.Time) time.Time {
+	return credited.AddDate(0, 0, p.ExpiryDays)
+}
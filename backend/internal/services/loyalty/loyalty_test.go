@@ -0,0 +1,60 @@
+package loyalty
+
+import (
+	"testing"
+	"time"
+)
+
+var program = Program{EarnPercent: 1, YenPerPoint: 1, ExpiryDays: 365}
+
+func TestEarned(t *testing.T) {
+	tests := []struct {
+		name string
+		paid int
+		want int
+	}{
+		{"one point per 100 yen", 5000, 50},
+		{"rounded down", 199, 1},
+		{"nothing paid", 0, 0},
+		{"negative amount", -500, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := program.Earned(tt.paid); got != tt.want {
+				t.Errorf("Earned(%d) = %d, want %d", tt.paid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedeemable(t *testing.T) {
+	tests := []struct {
+		name      string
+		program   Program
+		requested int
+		price     int
+		want      int
+	}{
+		{"requested amount", program, 300, 5000, 300},
+		{"capped at the price", program, 8000, 5000, 5000},
+		{"capped at whole points", Program{YenPerPoint: 2}, 8000, 5001, 2500},
+		{"negative request", program, -10, 5000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.program.Redeemable(tt.requested, tt.price); got != tt.want {
+				t.Errorf("Redeemable(%d, %d) = %d, want %d", tt.requested, tt.price, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	credited := time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC)
+	want := time.Date(2025, 2, 28, 10, 0, 0, 0, time.UTC)
+	if got := program.ExpiresAt(credited); !got.Equal(want) {
+		t.Errorf("ExpiresAt(%v) = %v, want %v", credited, got, want)
+	}
+}
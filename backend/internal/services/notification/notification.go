@@ -0,0 +1,128 @@
+// Package notification sends templated messages over email, SMS and push channels.
+//
+// Each channel is a Sender. The service renders the message for the recipient's locale
+// and sends it on every channel the recipient has enabled and has an address for.
+// Local sinks (log, file, in-memory) stand in for real providers.
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Channels
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// Message One rendered notification for one address
+type Message struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"` // Email address, phone number or push token
+	Event   string `json:"event"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject,omitempty"` // Email subject or push title
+	Body    string `json:"body"`
+
+	Attachments []Attachment `json:"attachments,omitempty"` // Email only
+}
+
+// Attachment File sent with an email
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"`
+}
+
+// Sender Delivers messages on one channel
+type Sender interface {
+	Channel() string
+	Send(ctx context.Context, message Message) error
+}
+
+// Recipient Addresses and preferences of the person notified
+type Recipient struct {
+	Email     string
+	Phone     string
+	PushToken string
+	Locale    string   // LocaleJapanese or LocaleEnglish; Japanese when empty
+	Channels  []string // Enabled channels
+}
+
+// EnabledChannels Channels turned on in a recipient's preferences, in a fixed order
+func EnabledChannels(email, sms, push bool) []string {
+	var channels []string
+	if email {
+		channels = append(channels, ChannelEmail)
+	}
+	if sms {
+		channels = append(channels, ChannelSMS)
+	}
+	if push {
+		channels = append(channels, ChannelPush)
+	}
+	return channels
+}
+
+// address Address of the recipient on a channel
+func (r Recipient) address(channel string) string {
+	switch channel {
+	case ChannelEmail:
+		return r.Email
+	case ChannelSMS:
+		return r.Phone
+	case ChannelPush:
+		return r.PushToken
+	default:
+		return ""
+	}
+}
+
+// Service Renders and sends notifications
+type Service struct {
+	senders map[string]Sender
+}
+
+// NewService Service sending through the given senders (one per channel; later ones win)
+func NewService(senders ...Sender) *Service {
+	s := &Service{senders: make(map[string]Sender, len(senders))}
+	for _, sender := range senders {
+		s.senders[sender.Channel()] = sender
+	}
+	return s
+}
+
+// Notify Send an event to a recipient on each enabled channel. Returns the messages sent;
+// a failing channel does not stop the others and its error is included in the result.
+// Attachments are only sent by email.
+func (s *Service) Notify(ctx context.Context, recipient Recipient, event string, data interface{}, attachments ...Attachment) ([]Message, error) {
+	var sent []Message
+	var errs []error
+	for _, channel := range recipient.Channels {
+		sender, ok := s.senders[channel]
+		to := recipient.address(channel)
+		if !ok || to == "" {
+			continue
+		}
+
+		message, err := Render(event, recipient.Locale, channel, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		message.To = to
+		if channel == ChannelEmail {
+			message.Attachments = attachments
+		}
+
+		if err := sender.Send(ctx, message); err != nil {
+			errs = append(errs, fmt.Errorf("%s to %s: %w", channel, to, err))
+			continue
+		}
+		sent = append(sent, message)
+	}
+	return sent, errors.Join(errs...)
+}
@@ -0,0 +1,194 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var reservation = Reservation{
+	ID:           42,
+	CustomerName: "山田 花子",
+	SalonName:    "Salon A",
+	ServiceName:  "Cut",
+	StaffName:    "Sato",
+	StartTime:    time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC),
+	TotalPrice:   12500,
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name        string
+		locale      string
+		channel     string
+		wantSubject string
+		wantBody    []string
+	}{
+		{"japanese email", LocaleJapanese, ChannelEmail, "【Salon A】ご予約を承りました", []string{"山田 花子 様", "2024年3月9日(土) 14:30", "12,500円"}},
+		{"english email", LocaleEnglish, ChannelEmail, "[Salon A] Your reservation is confirmed", []string{"Dear 山田 花子,", "Sat, Mar 9 2024 14:30", "¥12,500"}},
+		{"unknown locale falls back to japanese", "fr", ChannelEmail, "【Salon A】ご予約を承りました", []string{"2024年3月9日(土) 14:30"}},
+		{"sms has the short text and no subject", LocaleEnglish, ChannelSMS, "", []string{"Salon A: your Cut on Sat, Mar 9 2024 14:30 is confirmed."}},
+		{"push has a title and the short text", LocaleJapanese, ChannelPush, "【Salon A】ご予約を承りました", []string{"Salon A: 2024年3月9日(土) 14:30 Cut のご予約を承りました。"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := Render(EventReservationCreated, tt.locale, tt.channel, reservation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if message.Subject != tt.wantSubject {
+				t.Errorf("Subject = %q, want %q", message.Subject, tt.wantSubject)
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(message.Body, want) {
+					t.Errorf("Body = %q, want it to contain %q", message.Body, want)
+				}
+			}
+			if message.Channel != tt.channel || message.Event != EventReservationCreated {
+				t.Errorf("Render() = %+v, want channel %s and event %s", message, tt.channel, EventReservationCreated)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := Render("unknown.event", LocaleJapanese, ChannelEmail, reservation); err == nil {
+		t.Error("Render() of an unknown event succeeded, want an error")
+	}
+	if _, err := Render(EventReservationCreated, LocaleJapanese, ChannelEmail, map[string]interface{}{}); err == nil {
+		t.Error("Render() with missing data succeeded, want an error")
+	}
+}
+
+func TestEnabledChannels(t *testing.T) {
+	tests := []struct {
+		name             string
+		email, sms, push bool
+		want             []string
+	}{
+		{"email only", true, false, false, []string{ChannelEmail}},
+		{"all channels", true, true, true, []string{ChannelEmail, ChannelSMS, ChannelPush}},
+		{"sms and push", false, true, true, []string{ChannelSMS, ChannelPush}},
+		{"none", false, false, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EnabledChannels(tt.email, tt.sms, tt.push)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("EnabledChannels(%v, %v, %v) = %q, want %q", tt.email, tt.sms, tt.push, got, tt.want)
+			}
+		})
+	}
+}
+
+// failingSender Sender whose sends always fail
+type failingSender struct {
+	channel string
+}
+
+func (s failingSender) Channel() string {
+	return s.channel
+}
+
+func (s failingSender) Send(ctx context.Context, message Message) error {
+	return errors.New("provider unavailable")
+}
+
+func TestNotify(t *testing.T) {
+	attachment := Attachment{Filename: "reservation-42.ics", ContentType: "text/calendar", Content: "BEGIN:VCALENDAR"}
+
+	tests := []struct {
+		name      string
+		recipient Recipient
+		senders   []Sender
+		wantSent  []string // Channels sent on
+		wantErr   bool
+	}{
+		{
+			name:      "enabled channels with an address",
+			recipient: Recipient{Email: "hanako@example.com", Phone: "09012345678", Channels: []string{ChannelEmail, ChannelSMS}},
+			wantSent:  []string{ChannelEmail, ChannelSMS},
+		},
+		{
+			name:      "disabled channels are skipped",
+			recipient: Recipient{Email: "hanako@example.com", Phone: "09012345678", PushToken: "token", Channels: []string{ChannelPush}},
+			wantSent:  []string{ChannelPush},
+		},
+		{
+			name:      "channels without an address are skipped",
+			recipient: Recipient{Email: "hanako@example.com", Channels: []string{ChannelEmail, ChannelSMS, ChannelPush}},
+			wantSent:  []string{ChannelEmail},
+		},
+		{
+			name:      "a failing channel does not stop the others",
+			recipient: Recipient{Email: "hanako@example.com", Phone: "09012345678", Channels: []string{ChannelEmail, ChannelSMS}},
+			senders:   []Sender{failingSender{ChannelEmail}},
+			wantSent:  []string{ChannelSMS},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := map[string]*MemorySender{
+				ChannelEmail: NewMemorySender(ChannelEmail),
+				ChannelSMS:   NewMemorySender(ChannelSMS),
+				ChannelPush:  NewMemorySender(ChannelPush),
+			}
+			senders := []Sender{memory[ChannelEmail], memory[ChannelSMS], memory[ChannelPush]}
+			service := NewService(append(senders, tt.senders...)...)
+
+			sent, err := service.Notify(context.Background(), tt.recipient, EventReservationCreated, reservation, attachment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			var channels []string
+			for _, message := range sent {
+				channels = append(channels, message.Channel)
+				if stored := memory[message.Channel].Messages(); len(stored) != 1 || stored[0].To != message.To {
+					t.Errorf("%s sink holds %+v, want the message to %s", message.Channel, stored, message.To)
+				}
+
+				// The calendar file only goes out by email
+				wantAttachments := 0
+				if message.Channel == ChannelEmail {
+					wantAttachments = 1
+				}
+				if len(message.Attachments) != wantAttachments {
+					t.Errorf("%s message has %d attachments, want %d", message.Channel, len(message.Attachments), wantAttachments)
+				}
+			}
+			if strings.Join(channels, ",") != strings.Join(tt.wantSent, ",") {
+				t.Errorf("Notify() sent on %q, want %q", channels, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestLogSenderHidesBody(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	token := "verify-token-3f9a"
+	data := EmailVerification{Name: "Hanako", VerifyURL: "https://example.com/verify?token=" + token}
+	service := NewService(NewLogSender(ChannelEmail))
+	if _, err := service.Notify(context.Background(), Recipient{Email: "hanako@example.com", Channels: []string{ChannelEmail}}, EventEmailVerification, data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(output.String(), "hanako@example.com") {
+		t.Errorf("log has no recipient: %s", output.String())
+	}
+	if strings.Contains(output.String(), token) {
+		t.Errorf("log contains the verification token: %s", output.String())
+	}
+}
@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogSender Writes messages to the standard logger
+type LogSender struct {
+	channel string
+}
+
+// NewLogSender Log sink for a channel
+func NewLogSender(channel string) *LogSender {
+	return &LogSender{channel: channel}
+}
+
+// Channel Channel handled by the sink
+func (s *LogSender) Channel() string {
+	return s.channel
+}
+
+// Send Log that the message was sent (bodies carry verification and manage links, so only their size is logged)
+func (s *LogSender) Send(ctx context.Context, message Message) error {
+	log.Printf("[notification:%s] to=%s event=%s subject=%q body_bytes=%d attachments=%d", s.channel, message.To, message.Event, message.Subject, len(message.Body), len(message.Attachments))
+	return nil
+}
+
+// FileSender Appends messages to a file as JSON lines
+type FileSender struct {
+	channel string
+	path    string
+	mu      sync.Mutex
+}
+
+// NewFileSender File sink for a channel
+func NewFileSender(channel, path string) *FileSender {
+	return &FileSender{channel: channel, path: path}
+}
+
+// Channel Channel handled by the sink
+func (s *FileSender) Channel() string {
+	return s.channel
+}
+
+// Send Append the message to the file
+func (s *FileSender) Send(ctx context.Context, message Message) error {
+	line, err := json.Marshal(struct {
+		Message
+		SentAt time.Time `json:"sent_at"`
+	}{message, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// MemorySender Keeps messages in memory (for tests and demos)
+type MemorySender struct {
+	channel  string
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemorySender In-memory sink for a channel
+func NewMemorySender(channel string) *MemorySender {
+	return &MemorySender{channel: channel}
+}
+
+// Channel Channel handled by the sink
+func (s *MemorySender) Channel() string {
+	return s.channel
+}
+
+// Send Store the message
+func (s *MemorySender) Send(ctx context.Context, message Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+// Messages Messages sent so far
+func (s *MemorySender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Reset Forget the messages sent so far
+func (s *MemorySender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = nil
+}
@@ -0,0 +1,325 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Locales
+const (
+	LocaleJapanese = "ja"
+	LocaleEnglish  = "en"
+)
+
+// Events
+const (
+	EventReservationCreated   = "reservation.created"
+	EventReservationUpdated   = "reservation.updated"
+	EventReservationCancelled = "reservation.cancelled"
+	EventReservationReminder  = "reservation.reminder"
+
+	// Sent to the salon
+	EventSalonReservationCreated   = "salon.reservation.created"
+	EventSalonReservationUpdated   = "salon.reservation.updated"
+	EventSalonReservationCancelled = "salon.reservation.cancelled"
+
+	// Account events (email only)
+	EventEmailVerification = "account.email_verification"
+)
+
+// Reservation Data available to reservation templates
+type Reservation struct {
+	ID           uint
+	CustomerName string
+	SalonName    string
+	ServiceName  string
+	StaffName    string
+	StartTime    time.Time // In the salon's time zone
+	TotalPrice   int
+	ConfirmURL   string // Reminders only
+	CancelURL    string
+}
+
+// EmailVerification Data available to the email verification template
+type EmailVerification struct {
+	Name      string
+	VerifyURL string
+}
+
+// messageTemplate Texts of one event in one locale. Short is used for SMS and push bodies.
+type messageTemplate struct {
+	Subject string
+	Body    string
+	Short   string
+}
+
+var templates = map[string]map[string]messageTemplate{
+	EventReservationCreated: {
+		LocaleJapanese: {
+			Subject: "【{{.SalonName}}】ご予約を承りました",
+			Body: `{{.CustomerName}} 様
+
+{{.SalonName}} のご予約を承りました。
+
+日時: {{datetime .StartTime}}
+メニュー: {{.ServiceName}}
+担当: {{.StaffName}}
+料金: {{yen .TotalPrice}}
+
+ご来店をお待ちしております。`,
+			Short: "{{.SalonName}}: {{datetime .StartTime}} {{.ServiceName}} のご予約を承りました。",
+		},
+		LocaleEnglish: {
+			Subject: "[{{.SalonName}}] Your reservation is confirmed",
+			Body: `Dear {{.CustomerName}},
+
+Your reservation at {{.SalonName}} is confirmed.
+
+Date: {{datetime .StartTime}}
+Menu: {{.ServiceName}}
+Stylist: {{.StaffName}}
+Price: {{yen .TotalPrice}}
+
+We look forward to seeing you.`,
+			Short: "{{.SalonName}}: your {{.ServiceName}} on {{datetime .StartTime}} is confirmed.",
+		},
+	},
+	EventReservationUpdated: {
+		LocaleJapanese: {
+			Subject: "【{{.SalonName}}】ご予約内容が変更されました",
+			Body: `{{.CustomerName}} 様
+
+{{.SalonName}} のご予約内容が変更されました。
+
+日時: {{datetime .StartTime}}
+メニュー: {{.ServiceName}}
+担当: {{.StaffName}}
+料金: {{yen .TotalPrice}}`,
+			Short: "{{.SalonName}}: ご予約が {{datetime .StartTime}} {{.ServiceName}} に変更されました。",
+		},
+		LocaleEnglish: {
+			Subject: "[{{.SalonName}}] Your reservation has changed",
+			Body: `Dear {{.CustomerName}},
+
+Your reservation at {{.SalonName}} has changed.
+
+Date: {{datetime .StartTime}}
+Menu: {{.ServiceName}}
+Stylist: {{.StaffName}}
+Price: {{yen .TotalPrice}}`,
+			Short: "{{.SalonName}}: your reservation is now {{.ServiceName}} on {{datetime .StartTime}}.",
+		},
+	},
+	EventReservationCancelled: {
+		LocaleJapanese: {
+			Subject: "【{{.SalonName}}】ご予約がキャンセルされました",
+			Body: `{{.CustomerName}} 様
+
+{{.SalonName}} の以下のご予約はキャンセルされました。
+
+日時: {{datetime .StartTime}}
+メニュー: {{.ServiceName}}
+
+またのご利用をお待ちしております。`,
+			Short: "{{.SalonName}}: {{datetime .StartTime}} のご予約はキャンセルされました。",
+		},
+		LocaleEnglish: {
+			Subject: "[{{.SalonName}}] Your reservation was cancelled",
+			Body: `Dear {{.CustomerName}},
+
+Your reservation at {{.SalonName}} was cancelled.
+
+Date: {{datetime .StartTime}}
+Menu: {{.ServiceName}}
+
+We hope to see you again.`,
+			Short: "{{.SalonName}}: your reservation on {{datetime .StartTime}} was cancelled.",
+		},
+	},
+	EventReservationReminder: {
+		LocaleJapanese: {
+			Subject: "【{{.SalonName}}】ご予約のリマインダー",
+			Body: `{{.CustomerName}} 様
+
+{{.SalonName}} のご予約が近づいています。
+
+日時: {{datetime .StartTime}}
+メニュー: {{.ServiceName}}
+担当: {{.StaffName}}
+
+ご来店の確認: {{.ConfirmURL}}
+キャンセル: {{.CancelURL}}`,
+			Short: "{{.SalonName}}: {{datetime .StartTime}} {{.ServiceName}} のご予約です。確認: {{.ConfirmURL}}",
+		},
+		LocaleEnglish: {
+			Subject: "[{{.SalonName}}] Reminder of your reservation",
+			Body: `Dear {{.CustomerName}},
+
+Your reservation at {{.SalonName}} is coming up.
+
+Date: {{datetime .StartTime}}
+Menu: {{.ServiceName}}
+Stylist: {{.StaffName}}
+
+Confirm you are coming: {{.ConfirmURL}}
+Cancel: {{.CancelURL}}`,
+			Short: "{{.SalonName}}: reminder of your {{.ServiceName}} on {{datetime .StartTime}}. Confirm: {{.ConfirmURL}}",
+		},
+	},
+	EventEmailVerification: {
+		LocaleJapanese: {
+			Subject: "メールアドレスの確認",
+			Body: `{{.Name}} 様
+
+ご登録ありがとうございます。以下のリンクからメールアドレスを確認してください。
+ゲストとしてご予約いただいた内容もアカウントに引き継がれます。
+
+{{.VerifyURL}}`,
+		},
+		LocaleEnglish: {
+			Subject: "Confirm your email address",
+			Body: `Dear {{.Name}},
+
+Thank you for registering. Please confirm your email address with the link below.
+Reservations you made as a guest will be added to your account.
+
+{{.VerifyURL}}`,
+		},
+	},
+	EventSalonReservationCreated: {
+		LocaleJapanese: {
+			Subject: "【新規予約】{{datetime .StartTime}} {{.CustomerName}} 様",
+			Body:    salonBodyJapanese,
+			Short:   "新規予約: {{datetime .StartTime}} {{.CustomerName}} 様 {{.ServiceName}} ({{.StaffName}})",
+		},
+		LocaleEnglish: {
+			Subject: "[New reservation] {{datetime .StartTime}} {{.CustomerName}}",
+			Body:    salonBodyEnglish,
+			Short:   "New reservation: {{.CustomerName}}, {{.ServiceName}} with {{.StaffName}} on {{datetime .StartTime}}",
+		},
+	},
+	EventSalonReservationUpdated: {
+		LocaleJapanese: {
+			Subject: "【予約変更】{{datetime .StartTime}} {{.CustomerName}} 様",
+			Body:    salonBodyJapanese,
+			Short:   "予約変更: {{datetime .StartTime}} {{.CustomerName}} 様 {{.ServiceName}} ({{.StaffName}})",
+		},
+		LocaleEnglish: {
+			Subject: "[Reservation changed] {{datetime .StartTime}} {{.CustomerName}}",
+			Body:    salonBodyEnglish,
+			Short:   "Reservation changed: {{.CustomerName}}, {{.ServiceName}} with {{.StaffName}} on {{datetime .StartTime}}",
+		},
+	},
+	EventSalonReservationCancelled: {
+		LocaleJapanese: {
+			Subject: "【予約キャンセル】{{datetime .StartTime}} {{.CustomerName}} 様",
+			Body:    salonBodyJapanese,
+			Short:   "予約キャンセル: {{datetime .StartTime}} {{.CustomerName}} 様 {{.ServiceName}} ({{.StaffName}})",
+		},
+		LocaleEnglish: {
+			Subject: "[Reservation cancelled] {{datetime .StartTime}} {{.CustomerName}}",
+			Body:    salonBodyEnglish,
+			Short:   "Reservation cancelled: {{.CustomerName}}, {{.ServiceName}} with {{.StaffName}} on {{datetime .StartTime}}",
+		},
+	},
+}
+
+// Reservation details for salon notifications
+const (
+	salonBodyJapanese = `予約番号: {{.ID}}
+お客様: {{.CustomerName}} 様
+日時: {{datetime .StartTime}}
+メニュー: {{.ServiceName}}
+担当: {{.StaffName}}
+料金: {{yen .TotalPrice}}`
+	salonBodyEnglish = `Reservation: #{{.ID}}
+Customer: {{.CustomerName}}
+Date: {{datetime .StartTime}}
+Menu: {{.ServiceName}}
+Stylist: {{.StaffName}}
+Price: {{yen .TotalPrice}}`
+)
+
+// weekdaysJapanese Short Japanese weekday names, Sunday first
+var weekdaysJapanese = []string{"日", "月", "火", "水", "木", "金", "土"}
+
+// templateFuncs Locale-aware formatting helpers
+func templateFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"datetime": func(t time.Time) string {
+			if locale == LocaleEnglish {
+				return t.Format("Mon, Jan 2 2006 15:04")
+			}
+			return fmt.Sprintf("%s(%s) %s", t.Format("2006年1月2日"), weekdaysJapanese[t.Weekday()], t.Format("15:04"))
+		},
+		"yen": func(amount int) string {
+			if locale == LocaleEnglish {
+				return "¥" + groupThousands(amount)
+			}
+			return groupThousands(amount) + "円"
+		},
+	}
+}
+
+// groupThousands Format an integer with thousands separators
+func groupThousands(n int) string {
+	digits := fmt.Sprint(n)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+// Render Message of an event for a locale and channel (without the address).
+// Unknown locales fall back to Japanese.
+func Render(event, locale, channel string, data interface{}) (Message, error) {
+	byLocale, ok := templates[event]
+	if !ok {
+		return Message{}, fmt.Errorf("no template for event %s", event)
+	}
+	if _, ok := byLocale[locale]; !ok {
+		locale = LocaleJapanese
+	}
+	texts := byLocale[locale]
+
+	body := texts.Body
+	if channel != ChannelEmail {
+		body = texts.Short
+	}
+
+	message := Message{Channel: channel, Event: event, Locale: locale}
+	var err error
+	if channel != ChannelSMS {
+		if message.Subject, err = execute(texts.Subject, locale, data); err != nil {
+			return Message{}, err
+		}
+	}
+	if message.Body, err = execute(body, locale, data); err != nil {
+		return Message{}, err
+	}
+	return message, nil
+}
+
+// execute Render one template text
+func execute(text, locale string, data interface{}) (string, error) {
+	tmpl, err := template.New("").Funcs(templateFuncs(locale)).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,144 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Payment method tokens understood by the fake gateway
+const (
+	FakeTokenSuccess  = "tok_visa"
+	FakeTokenDeclined = "tok_declined"
+)
+
+// FakeGateway In-memory gateway for development and tests. Any payment method token starting
+// with "tok_" is accepted except FakeTokenDeclined. Webhooks are signed with HMAC-SHA256.
+type FakeGateway struct {
+	secret []byte
+
+	mu       sync.Mutex
+	sequence int
+	payments map[string]*Result
+	byKey    map[string]string // Idempotency key -> payment ID
+}
+
+// NewFakeGateway Create a fake gateway verifying webhooks with the secret
+func NewFakeGateway(secret []byte) *FakeGateway {
+	return &FakeGateway{
+		secret:   secret,
+		payments: make(map[string]*Result),
+		byKey:    make(map[string]string),
+	}
+}
+
+// Name Provider name
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+// Authorize Reserve the amount on the payment method
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	if req.Amount <= 0 {
+		return Result{}, ErrInvalidAmount
+	}
+	if !strings.HasPrefix(req.PaymentMethod, "tok_") || req.PaymentMethod == FakeTokenDeclined {
+		return Result{}, ErrDeclined
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if id, ok := g.byKey[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return *g.payments[id], nil
+	}
+
+	g.sequence++
+	payment := &Result{
+		PaymentID: fmt.Sprintf("fake_pay_%d", g.sequence),
+		Status:    StatusAuthorized,
+		Amount:    req.Amount,
+	}
+	g.payments[payment.PaymentID] = payment
+	if req.IdempotencyKey != "" {
+		g.byKey[req.IdempotencyKey] = payment.PaymentID
+	}
+	return *payment, nil
+}
+
+// Capture Charge (part of) the authorized amount
+func (g *FakeGateway) Capture(ctx context.Context, paymentID string, amount int) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[paymentID]
+	if !ok {
+		return Result{}, ErrNotFound
+	}
+	if payment.Status != StatusAuthorized {
+		return Result{}, ErrInvalidState
+	}
+	if amount <= 0 || amount > payment.Amount {
+		return Result{}, ErrInvalidAmount
+	}
+
+	payment.CapturedAmount = amount
+	payment.Status = StatusCaptured
+	return *payment, nil
+}
+
+// Refund Refund a captured amount, or release an uncaptured authorization
+func (g *FakeGateway) Refund(ctx context.Context, paymentID string, amount int) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment, ok := g.payments[paymentID]
+	if !ok {
+		return Result{}, ErrNotFound
+	}
+
+	switch payment.Status {
+	case StatusAuthorized:
+		payment.Status = StatusRefunded
+	case StatusCaptured, StatusPartiallyRefunded:
+		if amount <= 0 || payment.RefundedAmount+amount > payment.CapturedAmount {
+			return Result{}, ErrInvalidAmount
+		}
+		payment.RefundedAmount += amount
+		payment.Status = StatusPartiallyRefunded
+		if payment.RefundedAmount == payment.CapturedAmount {
+			payment.Status = StatusRefunded
+		}
+	default:
+		return Result{}, ErrInvalidState
+	}
+	return *payment, nil
+}
+
+// ParseWebhook Verify the hex HMAC-SHA256 signature of the payload and decode the event
+func (g *FakeGateway) ParseWebhook(payload []byte, signature string) (Event, error) {
+	var event Event
+	if !hmac.Equal([]byte(g.Sign(payload)), []byte(signature)) {
+		return event, ErrInvalidSignature
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return event, err
+	}
+	if event.ID == "" || event.PaymentID == "" {
+		return event, errors.New("incomplete webhook event")
+	}
+	return event, nil
+}
+
+// Sign Signature of a webhook payload, for sending test webhooks
+func (g *FakeGateway) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,133 @@
+// Package payment defines the payment gateway interface and the deposit and refund rules.
+//
+// Amounts are integer yen. A payment is authorized, then captured; refunds can be partial.
+// Gateways report changes made outside the API (e.g. disputes, refunds from a dashboard)
+// through webhooks, which are verified and parsed by the gateway itself.
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Payment statuses
+const (
+	StatusAuthorized        = "authorized"
+	StatusCaptured          = "captured"
+	StatusPartiallyRefunded = "partially_refunded"
+	StatusRefunded          = "refunded"
+	StatusFailed            = "failed"
+)
+
+// Webhook event types
+const (
+	EventCaptured = "payment.captured"
+	EventRefunded = "payment.refunded"
+	EventFailed   = "payment.failed"
+)
+
+// Salon payment policies
+const (
+	PolicyNone    = ""        // Pay at the salon
+	PolicyDeposit = "deposit" // Part of the price is charged at booking time
+	PolicyPrepay  = "prepay"  // The full price is charged at booking time
+)
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrNotFound         = errors.New("payment not found")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrInvalidState     = errors.New("payment is not in a state that allows this operation")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// AuthorizeRequest Request to reserve an amount on a payment method
+type AuthorizeRequest struct {
+	Amount         int
+	PaymentMethod  string // Token issued by the gateway's client-side SDK
+	Description    string
+	IdempotencyKey string // Retrying with the same key returns the original authorization
+}
+
+// Result State of a payment at the gateway after an operation
+type Result struct {
+	PaymentID      string
+	Status         string
+	Amount         int // Authorized amount
+	CapturedAmount int
+	RefundedAmount int
+}
+
+// Event Payment change reported by a gateway webhook
+type Event struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	PaymentID string `json:"payment_id"`
+	Amount    int    `json:"amount"` // Total captured or refunded amount after the event
+}
+
+// Gateway Payment provider
+type Gateway interface {
+	// Name Provider name stored with each payment
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	Capture(ctx context.Context, paymentID string, amount int) (Result, error)
+	// Refund Refund a captured amount, or release an authorization that was not captured
+	Refund(ctx context.Context, paymentID string, amount int) (Result, error)
+	// ParseWebhook Verify the signature of a webhook request and decode its event
+	ParseWebhook(payload []byte, signature string) (Event, error)
+}
+
+// Policy Salon rules for charging at booking time and refunding on cancellation
+type Policy struct {
+	Mode                          string // PolicyNone, PolicyDeposit or PolicyPrepay
+	DepositPercent                int    // Share of the price charged as a deposit
+	FreeCancellationHours         int    // Full refund when cancelled at least this long before the start
+	LateCancellationRefundPercent int    // Share refunded when cancelled later than that
+}
+
+// Validate Check the policy settings
+func (p Policy) Validate() error {
+	switch p.Mode {
+	case PolicyNone, PolicyPrepay:
+	case PolicyDeposit:
+		if p.DepositPercent <= 0 || p.DepositPercent > 100 {
+			return errors.New("deposit percent must be between 1 and 100")
+		}
+	default:
+		return errors.New("invalid payment policy")
+	}
+	if p.FreeCancellationHours < 0 {
+		return errors.New("free cancellation hours must not be negative")
+	}
+	if p.LateCancellationRefundPercent < 0 || p.LateCancellationRefundPercent > 100 {
+		return errors.New("late cancellation refund percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// AmountDue Amount to charge at booking time for a price (0 when paid at the salon)
+func (p Policy) AmountDue(price int) int {
+	switch p.Mode {
+	case PolicyPrepay:
+		return price
+	case PolicyDeposit:
+		// Rounded up so that small prices still take a deposit
+		return (price*p.DepositPercent + 99) / 100
+	default:
+		return 0
+	}
+}
+
+// RefundAmount Amount to give back when a customer cancels, from what was captured
+func (p Policy) RefundAmount(captured int, start, cancelledAt time.Time) int {
+	if captured <= 0 {
+		return 0
+	}
+	if start.Sub(cancelledAt) >= time.Duration(p.FreeCancellationHours)*time.Hour {
+		return captured
+	}
+	// Rounded down in the salon's favour
+	return captured * p.LateCancellationRefundPercent / 100
+}
@@ -0,0 +1,77 @@
+package payment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"pay at the salon", Policy{}, false},
+		{"prepay", Policy{Mode: PolicyPrepay, FreeCancellationHours: 24, LateCancellationRefundPercent: 50}, false},
+		{"deposit", Policy{Mode: PolicyDeposit, DepositPercent: 30}, false},
+		{"deposit without percent", Policy{Mode: PolicyDeposit}, true},
+		{"deposit over 100 percent", Policy{Mode: PolicyDeposit, DepositPercent: 101}, true},
+		{"unknown mode", Policy{Mode: "later"}, true},
+		{"negative free cancellation hours", Policy{FreeCancellationHours: -1}, true},
+		{"late refund over 100 percent", Policy{LateCancellationRefundPercent: 101}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyAmountDue(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		price  int
+		want   int
+	}{
+		{"pay at the salon", Policy{}, 5000, 0},
+		{"prepay", Policy{Mode: PolicyPrepay}, 5000, 5000},
+		{"deposit", Policy{Mode: PolicyDeposit, DepositPercent: 30}, 5000, 1500},
+		{"deposit rounded up", Policy{Mode: PolicyDeposit, DepositPercent: 30}, 1001, 301},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.AmountDue(tt.price); got != tt.want {
+				t.Errorf("AmountDue(%d) = %d, want %d", tt.price, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyRefundAmount(t *testing.T) {
+	policy := Policy{Mode: PolicyPrepay, FreeCancellationHours: 24, LateCancellationRefundPercent: 50}
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		captured    int
+		cancelledAt time.Time
+		want        int
+	}{
+		{"full refund before the free window ends", 5001, start.Add(-24 * time.Hour), 5001},
+		{"partial refund rounded down when late", 5001, start.Add(-23 * time.Hour), 2500},
+		{"nothing captured", 0, start.Add(-48 * time.Hour), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.RefundAmount(tt.captured, start, tt.cancelledAt); got != tt.want {
+				t.Errorf("RefundAmount(%d) = %d, want %d", tt.captured, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package pricing builds itemized reservation prices.
+//
+// A price is a list of lines: the base price of the service followed by adjustments
+// (negative amounts for discounts). The total is the sum of the lines and never negative.
+package pricing
+
+import "time"
+
+// Line types
+const (
+	LineService    = "service"
+	LineStaffLevel = "staff_level" // Stylist level tier
+	LineSurcharge  = "surcharge"   // Day or time of day adjustment
+	LinePromotion  = "promotion"   // Automatic promotion
+	LineCoupon     = "coupon"
+	LinePoints     = "points"     // Loyalty points redeemed
+	LineAdjustment = "adjustment" // Manual price change by the salon
+)
+
+// Discount types
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+)
+
+// Line One item of a price breakdown
+type Line struct {
+	Type      string `json:"type"`
+	Label     string `json:"label"`
+	Amount    int    `json:"amount"`              // Yen; negative for discounts
+	Reference uint   `json:"reference,omitempty"` // ID of the service, promotion, ... behind the line
+}
+
+// Total Sum of the lines (never below zero)
+func Total(lines []Line) int {
+	total := 0
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return max(total, 0)
+}
+
+// Adjustment Percentage or fixed amount added to a price (negative to reduce it)
+type Adjustment struct {
+	Type  string // DiscountPercent or DiscountFixed
+	Value int
+}
+
+// Amount Adjustment of a price (rounded toward zero)
+func (a Adjustment) Amount(price int) int {
+	if a.Type == DiscountPercent {
+		return price * a.Value / 100
+	}
+	return a.Value
+}
+
+// Discount Percentage or fixed amount off a price
+type Discount struct {
+	Type  string
+	Value int
+}
+
+// Amount Discount on a price (never more than the price)
+func (d Discount) Amount(price int) int {
+	var amount int
+	switch d.Type {
+	case DiscountPercent:
+		// Rounded down in the salon's favour
+		amount = price * d.Value / 100
+	case DiscountFixed:
+		amount = d.Value
+	}
+	return min(max(amount, 0), price)
+}
+
+// Window Days and time of day a price rule applies to, in salon local time
+type Window struct {
+	Weekdays  []int  // 0 = Sunday; empty for every day
+	StartTime string // "15:04"; empty for all day
+	EndTime   string
+}
+
+// Contains Whether a local time falls in the window (the end time is exclusive)
+func (w Window) Contains(t time.Time) bool {
+	if len(w.Weekdays) > 0 {
+		found := false
+		for _, day := range w.Weekdays {
+			if time.Weekday(day) == t.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if w.StartTime != "" {
+		clock := t.Format("15:04")
+		if clock < w.StartTime || clock >= w.EndTime {
+			return false
+		}
+	}
+	return true
+}
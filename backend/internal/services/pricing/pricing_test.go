@@ -0,0 +1,100 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []Line
+		want  int
+	}{
+		{"no lines", nil, 0},
+		{"service with a discount", []Line{{Type: LineService, Amount: 5000}, {Type: LinePromotion, Amount: -500}}, 4500},
+		{"never below zero", []Line{{Type: LineService, Amount: 1000}, {Type: LineCoupon, Amount: -1500}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Total(tt.lines); got != tt.want {
+				t.Errorf("Total() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscountAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount Discount
+		price    int
+		want     int
+	}{
+		{"percent", Discount{Type: DiscountPercent, Value: 10}, 5000, 500},
+		{"percent rounded down", Discount{Type: DiscountPercent, Value: 15}, 999, 149},
+		{"fixed", Discount{Type: DiscountFixed, Value: 500}, 5000, 500},
+		{"fixed capped at the price", Discount{Type: DiscountFixed, Value: 3000}, 2000, 2000},
+		{"nothing off a free price", Discount{Type: DiscountPercent, Value: 50}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.discount.Amount(tt.price); got != tt.want {
+				t.Errorf("Amount(%d) = %d, want %d", tt.price, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	window := Window{Weekdays: []int{int(time.Saturday), int(time.Sunday)}, StartTime: "10:00", EndTime: "12:00"}
+	saturday := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"start is included", saturday.Add(10 * time.Hour), true},
+		{"end is excluded", saturday.Add(12 * time.Hour), false},
+		{"before the start", saturday.Add(9*time.Hour + 59*time.Minute), false},
+		{"other weekday", saturday.AddDate(0, 0, 2).Add(11 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := window.Contains(tt.t); got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+
+	if !(Window{}).Contains(saturday) {
+		t.Error("an empty window should contain every time")
+	}
+}
+
+func TestAdjustmentAmount(t *testing.T) {
+	tests := []struct {
+		name       string
+		adjustment Adjustment
+		price      int
+		want       int
+	}{
+		{"percent surcharge", Adjustment{Type: DiscountPercent, Value: 20}, 5000, 1000},
+		{"surcharge rounded toward zero", Adjustment{Type: DiscountPercent, Value: 15}, 999, 149},
+		{"reduction rounded toward zero", Adjustment{Type: DiscountPercent, Value: -15}, 999, -149},
+		{"fixed", Adjustment{Type: DiscountFixed, Value: 500}, 5000, 500},
+		{"fixed reduction", Adjustment{Type: DiscountFixed, Value: -500}, 5000, -500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.adjustment.Amount(tt.price); got != tt.want {
+				t.Errorf("Amount(%d) = %d, want %d", tt.price, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Package realtime fans reservation and slot hold changes out to live subscribers.
+//
+// Changes are published with PostgreSQL NOTIFY from the transaction that makes them, so they
+// are only seen once committed and reach every server instance. Listen receives them on a
+// dedicated connection and hands them on, typically to a Broker, which delivers them to the
+// streams subscribed to the salon.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"reservation-platform-sample/internal/services/webhook"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Channel PostgreSQL notification channel
+const Channel = "reservation_changes"
+
+// Change types besides the reservation events (reservation.created, reservation.updated, ...)
+const (
+	ChangeHoldCreated  = "hold.created"
+	ChangeHoldReleased = "hold.released"
+)
+
+// subscriberBuffer Changes queued per subscriber; a subscriber that falls further behind misses changes
+const subscriberBuffer = 64
+
+// Change Something that affects a salon's availability
+type Change struct {
+	Type          string    `json:"type"`
+	SalonID       uint      `json:"salon_id"`
+	StaffID       uint      `json:"staff_id"`
+	Date          string    `json:"date"` // Salon date of the reservation or hold ("2006-01-02")
+	ReservationID uint      `json:"reservation_id,omitempty"`
+	HoldID        uint      `json:"hold_id,omitempty"`
+	At            time.Time `json:"at"`
+}
+
+// Affects Whether the change may alter a day's slots for one staff member, or for all of them when staffID is 0.
+// A moved reservation also frees its previous date and staff member, which the change doesn't name.
+func (c Change) Affects(date string, staffID uint) bool {
+	if c.Type == webhook.EventReservationUpdated {
+		return true
+	}
+	return c.Date == date && (staffID == 0 || c.StaffID == staffID)
+}
+
+// Subscription Changes of one salon
+type Subscription struct {
+	salonID uint
+	changes chan Change
+}
+
+// Changes Channel receiving the changes
+func (s *Subscription) Changes() <-chan Change {
+	return s.changes
+}
+
+// Broker Delivers changes to the subscriptions of their salon
+type Broker struct {
+	mu   sync.RWMutex
+	subs map[uint]map[*Subscription]struct{}
+}
+
+// NewBroker Broker without subscriptions
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[uint]map[*Subscription]struct{})}
+}
+
+// Subscribe Start receiving the changes of a salon until the context ends
+func (b *Broker) Subscribe(ctx context.Context, salonID uint) *Subscription {
+	s := &Subscription{salonID: salonID, changes: make(chan Change, subscriberBuffer)}
+
+	b.mu.Lock()
+	if b.subs[salonID] == nil {
+		b.subs[salonID] = make(map[*Subscription]struct{})
+	}
+	b.subs[salonID][s] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.Unsubscribe(s)
+	}()
+	return s
+}
+
+// Unsubscribe Stop receiving changes before the subscription's context ends
+func (b *Broker) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs[s.salonID], s)
+	if len(b.subs[s.salonID]) == 0 {
+		delete(b.subs, s.salonID)
+	}
+}
+
+// Publish Deliver a change without waiting for slow subscribers
+func (b *Broker) Publish(change Change) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs[change.SalonID] {
+		select {
+		case s.changes <- change:
+		default:
+		}
+	}
+}
+
+// Listen Receive the changes notified on Channel and pass them to handle until the context ends,
+// reconnecting after failures
+func Listen(ctx context.Context, databaseURL string, handle func(Change)) {
+	for {
+		err := listen(ctx, databaseURL, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Live updates listener stopped: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// listen Receive notifications on one connection
+func listen(ctx context.Context, databaseURL string, handle func(Change)) error {
+	conn, err := pgx.Connect(ctx, databaseURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var change Change
+		if err := json.Unmarshal([]byte(notification.Payload), &change); err != nil {
+			log.Printf("Ignoring malformed change notification: %v", err)
+			continue
+		}
+		handle(change)
+	}
+}
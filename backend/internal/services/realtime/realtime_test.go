@@ -0,0 +1,134 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"reservation-platform-sample/internal/services/webhook"
+)
+
+// received Changes waiting on a subscription
+func received(s *Subscription) []Change {
+	var changes []Change
+	for {
+		select {
+		case change := <-s.Changes():
+			changes = append(changes, change)
+		default:
+			return changes
+		}
+	}
+}
+
+// subscribers Number of subscriptions to a salon
+func (b *Broker) subscribers(salonID uint) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs[salonID])
+}
+
+func TestBrokerPublish(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := NewBroker()
+	first, second := broker.Subscribe(ctx, 1), broker.Subscribe(ctx, 1)
+	other := broker.Subscribe(ctx, 2)
+
+	change := Change{Type: ChangeHoldCreated, SalonID: 1, StaffID: 3, Date: "2026-01-05", HoldID: 9}
+	broker.Publish(change)
+
+	for name, s := range map[string]*Subscription{"first": first, "second": second} {
+		if got := received(s); len(got) != 1 || got[0] != change {
+			t.Errorf("%s subscriber received %+v, want the change", name, got)
+		}
+	}
+	if got := received(other); len(got) != 0 {
+		t.Errorf("subscriber of another salon received %+v", got)
+	}
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := NewBroker()
+	s := broker.Subscribe(ctx, 1)
+
+	// Publishing never blocks; changes past the buffer are dropped
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < subscriberBuffer*2; i++ {
+			broker.Publish(Change{Type: ChangeHoldCreated, SalonID: 1})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish() blocked on a subscriber that doesn't read")
+	}
+
+	if got := len(received(s)); got != subscriberBuffer {
+		t.Errorf("subscriber received %d changes, want %d", got, subscriberBuffer)
+	}
+}
+
+func TestBrokerUnsubscribe(t *testing.T) {
+	broker := NewBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := broker.Subscribe(ctx, 1)
+	kept := broker.Subscribe(context.Background(), 1)
+
+	// Ending the context unsubscribes
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for broker.subscribers(1) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d subscribers after the context ended, want 1", broker.subscribers(1))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	broker.Publish(Change{Type: ChangeHoldReleased, SalonID: 1})
+	if got := received(s); len(got) != 0 {
+		t.Errorf("subscriber received %+v after its context ended", got)
+	}
+	if got := received(kept); len(got) != 1 {
+		t.Errorf("remaining subscriber received %+v, want the change", got)
+	}
+
+	// Unsubscribing twice or after the context ended is harmless
+	broker.Unsubscribe(s)
+	broker.Unsubscribe(kept)
+	if n := broker.subscribers(1); n != 0 {
+		t.Errorf("%d subscribers after unsubscribing, want 0", n)
+	}
+}
+
+func TestChangeAffects(t *testing.T) {
+	const date = "2026-01-05"
+
+	tests := []struct {
+		name    string
+		change  Change
+		staffID uint
+		want    bool
+	}{
+		{"same day, any staff", Change{Type: ChangeHoldCreated, StaffID: 3, Date: date}, 0, true},
+		{"same day and staff", Change{Type: webhook.EventReservationCreated, StaffID: 3, Date: date}, 3, true},
+		{"same day, other staff", Change{Type: webhook.EventReservationCreated, StaffID: 4, Date: date}, 3, false},
+		{"other day", Change{Type: webhook.EventReservationCancelled, StaffID: 3, Date: "2026-01-06"}, 3, false},
+		{"other day, any staff", Change{Type: ChangeHoldReleased, StaffID: 3, Date: "2026-01-06"}, 0, false},
+		{"moved reservation from another day and staff", Change{Type: webhook.EventReservationUpdated, StaffID: 4, Date: "2026-01-06"}, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.Affects(date, tt.staffID); got != tt.want {
+				t.Errorf("Affects(%q, %d) = %v, want %v", date, tt.staffID, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package reminder decides when appointment reminders are due.
+//
+// Reminders are sent at offsets before the start of a reservation (e.g. 24h and 2h).
+// Only the nearest offset that has passed is due, so a scheduler that was down does not
+// send several stale reminders at once, and reservations booked after an offset had
+// already passed do not get that reminder right after booking. A failed send is retried a
+// few times, but only while its offset is still the one due. A claim whose sender stopped
+// before recording the outcome is taken over once its lease runs out.
+package reminder
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxAttempts Sends of one reminder before giving up
+const MaxAttempts = 3
+
+// ClaimLease How long a claimed reminder may stay without an outcome before another run claims it
+// again. Far longer than a send takes, so a reminder still being sent is not sent twice.
+const ClaimLease = 15 * time.Minute
+
+// Claim Outcome so far of a claimed reminder
+type Claim struct {
+	Sent      bool
+	Failed    bool
+	Attempts  int
+	ClaimedAt time.Time
+}
+
+// Retry Whether a claimed reminder is claimed again for another attempt: its send failed, or its
+// sender stopped before recording the outcome and the lease ran out
+func (c Claim) Retry(now time.Time) bool {
+	if c.Sent || c.Attempts >= MaxAttempts {
+		return false
+	}
+	return c.Failed || !now.Before(c.ClaimedAt.Add(ClaimLease))
+}
+
+// ParseOffsets Parse a comma-separated list of durations ("24h,2h"), largest first
+func ParseOffsets(value string) ([]time.Duration, error) {
+	var offsets []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		offset, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		if offset <= 0 {
+			return nil, errors.New("reminder offsets must be positive")
+		}
+		offsets = append(offsets, offset)
+	}
+
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+	return offsets, nil
+}
+
+// Due Offset whose reminder is due now for a reservation, if any. Offsets are largest first.
+func Due(offsets []time.Duration, start, bookedAt, now time.Time) (time.Duration, bool) {
+	remaining := start.Sub(now)
+	if remaining <= 0 {
+		return 0, false
+	}
+
+	for i := len(offsets) - 1; i >= 0; i-- {
+		if remaining <= offsets[i] {
+			// Booked after the reminder time: the booking confirmation is enough
+			if bookedAt.After(start.Add(-offsets[i])) {
+				return 0, false
+			}
+			return offsets[i], true
+		}
+	}
+	return 0, false
+}
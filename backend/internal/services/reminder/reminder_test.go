@@ -0,0 +1,98 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []time.Duration
+		wantErr bool
+	}{
+		{"largest first", "2h,24h", []time.Duration{24 * time.Hour, 2 * time.Hour}, false},
+		{"spaces and empty parts", " 24h, ,30m ", []time.Duration{24 * time.Hour, 30 * time.Minute}, false},
+		{"disabled", "", nil, false},
+		{"invalid duration", "1d", nil, true},
+		{"not positive", "24h,0s", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOffsets(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOffsets(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseOffsets(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ParseOffsets(%q) = %v, want %v", tt.value, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDue(t *testing.T) {
+	offsets := []time.Duration{24 * time.Hour, 2 * time.Hour}
+	start := time.Date(2024, 3, 10, 14, 0, 0, 0, time.UTC)
+	bookedEarly := start.AddDate(0, 0, -7)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		bookedAt   time.Time
+		wantOffset time.Duration
+		wantDue    bool
+	}{
+		{"before the first offset", start.Add(-25 * time.Hour), bookedEarly, 0, false},
+		{"at the first offset", start.Add(-24 * time.Hour), bookedEarly, 24 * time.Hour, true},
+		{"between the offsets", start.Add(-5 * time.Hour), bookedEarly, 24 * time.Hour, true},
+		{"nearest passed offset only", start.Add(-time.Hour), bookedEarly, 2 * time.Hour, true},
+		{"booked after the nearest offset", start.Add(-time.Hour), start.Add(-90 * time.Minute), 0, false},
+		{"booked between the offsets", start.Add(-time.Hour), start.Add(-5 * time.Hour), 2 * time.Hour, true},
+		{"booked after the first offset", start.Add(-5 * time.Hour), start.Add(-6 * time.Hour), 0, false},
+		{"already started", start, bookedEarly, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, due := Due(offsets, start, tt.bookedAt, tt.now)
+			if offset != tt.wantOffset || due != tt.wantDue {
+				t.Errorf("Due() = %v, %v, want %v, %v", offset, due, tt.wantOffset, tt.wantDue)
+			}
+		})
+	}
+}
+
+func TestClaimRetry(t *testing.T) {
+	claimedAt := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	withinLease := claimedAt.Add(ClaimLease - time.Minute)
+	afterLease := claimedAt.Add(ClaimLease)
+
+	tests := []struct {
+		name  string
+		claim Claim
+		now   time.Time
+		want  bool
+	}{
+		{"sent", Claim{Sent: true, Attempts: 1, ClaimedAt: claimedAt}, afterLease, false},
+		{"failed", Claim{Failed: true, Attempts: 1, ClaimedAt: claimedAt}, withinLease, true},
+		{"failed too often", Claim{Failed: true, Attempts: MaxAttempts, ClaimedAt: claimedAt}, afterLease, false},
+		{"being sent", Claim{Attempts: 1, ClaimedAt: claimedAt}, withinLease, false},
+		{"abandoned past the lease", Claim{Attempts: 1, ClaimedAt: claimedAt}, afterLease, true},
+		{"abandoned on the last attempt", Claim{Attempts: MaxAttempts, ClaimedAt: claimedAt}, afterLease, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claim.Retry(tt.now); got != tt.want {
+				t.Errorf("%+v.Retry(%v) = %v, want %v", tt.claim, tt.now, got, tt.want)
+			}
+		})
+	}
+}
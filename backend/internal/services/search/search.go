@@ -0,0 +1,214 @@
+// Package search provides n-gram full-text search helpers for Japanese and mixed-script text.
+//
+// Text is normalized (NFKC, lower case, katakana folded to hiragana) and split into
+// character bigrams, which are stored as a PostgreSQL tsvector. Lexemes are written as
+// tsvector/tsquery literals so that the database text parser (which depends on the locale
+// and does not segment Japanese) is never involved.
+package search
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Weight Relevance weight of a field (A is the most relevant)
+type Weight byte
+
+const (
+	WeightA Weight = 'A'
+	WeightB Weight = 'B'
+	WeightC Weight = 'C'
+	WeightD Weight = 'D'
+)
+
+// Field Text indexed with a weight
+type Field struct {
+	Text   string
+	Weight Weight
+}
+
+// maxPosition Largest position PostgreSQL accepts in a tsvector
+const maxPosition = 16383
+
+// snippetRadius Number of characters shown around the first match in a snippet
+const snippetRadius = 30
+
+// normalizeRune Normalize a single character for matching
+func normalizeRune(r rune) []rune {
+	var normalized []rune
+	for _, n := range norm.NFKC.String(string(r)) {
+		n = unicode.ToLower(n)
+		// Katakana to hiragana so that readings match in either script
+		if n >= 'ァ' && n <= 'ヶ' {
+			n -= 'ァ' - 'ぁ'
+		}
+		normalized = append(normalized, n)
+	}
+	return normalized
+}
+
+// Normalize Normalize text for matching
+func Normalize(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		for _, n := range normalizeRune(r) {
+			b.WriteRune(n)
+		}
+	}
+	return b.String()
+}
+
+// isTokenRune Check whether a character belongs to a searchable run
+func isTokenRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == 'ー'
+}
+
+// runs Split normalized text into runs of searchable characters
+func runs(text string) [][]rune {
+	var result [][]rune
+	var current []rune
+	for _, r := range Normalize(text) {
+		if isTokenRune(r) {
+			current = append(current, r)
+			continue
+		}
+		if len(current) > 0 {
+			result = append(result, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		result = append(result, current)
+	}
+	return result
+}
+
+// Tokens Split text into bigrams (a single character run becomes a unigram)
+func Tokens(text string) []string {
+	var tokens []string
+	for _, run := range runs(text) {
+		if len(run) == 1 {
+			tokens = append(tokens, string(run))
+			continue
+		}
+		for i := 0; i+1 < len(run); i++ {
+			tokens = append(tokens, string(run[i:i+2]))
+		}
+	}
+	return tokens
+}
+
+// quoteLexeme Quote a lexeme for a tsvector/tsquery literal
+func quoteLexeme(lexeme string) string {
+	lexeme = strings.ReplaceAll(lexeme, `\`, `\\`)
+	lexeme = strings.ReplaceAll(lexeme, `'`, `''`)
+	return "'" + lexeme + "'"
+}
+
+// Vector Build a tsvector literal from weighted fields
+func Vector(fields ...Field) string {
+	positions := make(map[string][]string)
+	var order []string
+	position := 1
+
+	for _, field := range fields {
+		for _, token := range Tokens(field.Text) {
+			if _, seen := positions[token]; !seen {
+				order = append(order, token)
+			}
+			positions[token] = append(positions[token], fmt.Sprintf("%d%c", position, field.Weight))
+			if position < maxPosition {
+				position++
+			}
+		}
+	}
+
+	lexemes := make([]string, 0, len(order))
+	for _, token := range order {
+		lexemes = append(lexemes, quoteLexeme(token)+":"+strings.Join(positions[token], ","))
+	}
+	return strings.Join(lexemes, " ")
+}
+
+// Query Build a tsquery literal matching documents that contain every search term.
+// Returns an empty string when the text has no searchable characters.
+func Query(text string) string {
+	var terms []string
+	for _, run := range runs(text) {
+		// A single character also matches the bigrams starting with it
+		if len(run) == 1 {
+			terms = append(terms, quoteLexeme(string(run))+":*")
+			continue
+		}
+		for i := 0; i+1 < len(run); i++ {
+			terms = append(terms, quoteLexeme(string(run[i:i+2])))
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+// Highlight Build an HTML snippet of text around the first match of the query,
+// with every matched term wrapped in <mark>. Returns an empty string when nothing matches.
+func Highlight(text, query string) string {
+	// Normalize per character, remembering which original character each normalized one came from
+	original := []rune(text)
+	var normalized []rune
+	var origin []int
+	for i, r := range original {
+		for _, n := range normalizeRune(r) {
+			normalized = append(normalized, n)
+			origin = append(origin, i)
+		}
+	}
+
+	// Mark original characters covered by any query term
+	marked := make([]bool, len(original))
+	first := -1
+	for _, term := range runs(query) {
+		for start := 0; start+len(term) <= len(normalized); start++ {
+			if string(normalized[start:start+len(term)]) != string(term) {
+				continue
+			}
+			for i := start; i < start+len(term); i++ {
+				marked[origin[i]] = true
+			}
+			if first == -1 || origin[start] < first {
+				first = origin[start]
+			}
+		}
+	}
+	if first == -1 {
+		return ""
+	}
+
+	from := max(first-snippetRadius, 0)
+	to := min(first+snippetRadius, len(original))
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	inMark := false
+	for i := from; i < to; i++ {
+		if marked[i] != inMark {
+			if marked[i] {
+				b.WriteString("<mark>")
+			} else {
+				b.WriteString("</mark>")
+			}
+			inMark = marked[i]
+		}
+		b.WriteString(html.EscapeString(string(original[i])))
+	}
+	if inMark {
+		b.WriteString("</mark>")
+	}
+	if to < len(original) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package search
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"full width to half width", "ＡＢＣ１２３", "abc123"},
+		{"lower case", "Hair Salon", "hair salon"},
+		{"katakana to hiragana", "カット", "かっと"},
+		{"half width katakana", "ｶｯﾄ", "かっと"},
+		{"long vowel mark kept", "カラー", "からー"},
+		{"kanji unchanged", "美容室", "美容室"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"bigrams", "カット", []string{"かっ", "っと"}},
+		{"single character", "髪", []string{"髪"}},
+		{"split on punctuation and spaces", "ab, cd", []string{"ab", "cd"}},
+		{"nothing searchable", "!?", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokens(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Tokens(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Tokens(%q) = %q, want %q", tt.text, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"bigrams joined with and", "カット", "'かっ' & 'っと'"},
+		{"terms across words", "ab cd", "'ab' & 'cd'"},
+		{"single character is a prefix", "髪", "'髪':*"},
+		{"quotes escaped", "it's", "'it' & 's':*"},
+		{"backslash is not searchable", `a\b`, "'a':* & 'b':*"},
+		{"empty without searchable characters", "  !? ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Query(tt.text); got != tt.want {
+				t.Errorf("Query(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteLexeme(t *testing.T) {
+	if got, want := quoteLexeme(`a'b\c`), `'a''b\\c'`; got != want {
+		t.Errorf("quoteLexeme = %q, want %q", got, want)
+	}
+}
+
+func TestVector(t *testing.T) {
+	got := Vector(
+		Field{Text: "カット", Weight: WeightA},
+		Field{Text: "かっ", Weight: WeightC},
+	)
+	want := "'かっ':1A,3C 'っと':2A"
+	if got != want {
+		t.Errorf("Vector = %q, want %q", got, want)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		want  string
+	}{
+		{"marks matches in the original script", "ヘアカット専門店", "かっと", "ヘア<mark>カット</mark>専門店"},
+		{"escapes html", "<b>Cut</b>", "cut", "&lt;b&gt;<mark>Cut</mark>&lt;/b&gt;"},
+		{"no match", "カラー", "パーマ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.query); got != tt.want {
+				t.Errorf("Highlight(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Package webhook signs and delivers outgoing webhook events.
+//
+// Each request carries the event as a JSON envelope and an X-Webhook-Signature header
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>" with the endpoint secret>".
+// Receivers should check the signature and reject old timestamps. Failed deliveries are
+// retried with exponential backoff up to MaxAttempts.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reservation-platform-sample/internal/services/safehttp"
+)
+
+// Event types
+const (
+	EventReservationCreated   = "reservation.created"
+	EventReservationUpdated   = "reservation.updated"
+	EventReservationCancelled = "reservation.cancelled"
+	EventReservationCompleted = "reservation.completed"
+)
+
+// Events All event types endpoints can subscribe to
+var Events = []string{EventReservationCreated, EventReservationUpdated, EventReservationCancelled, EventReservationCompleted}
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed" // Gave up after MaxAttempts
+)
+
+// Retry schedule
+const (
+	MaxAttempts  = 8
+	firstBackoff = 30 * time.Second
+	maxBackoff   = 6 * time.Hour
+)
+
+// Request headers
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Id"
+)
+
+// maxResponseBody Bytes of the receiver's response read (and discarded) so the connection can be reused
+const maxResponseBody = 64 << 10
+
+// Envelope Body of a webhook request
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Result Outcome of one delivery attempt
+type Result struct {
+	StatusCode int // The response body is never kept, so receivers can't be used to read internal pages
+	Duration   time.Duration
+}
+
+// Succeeded Whether the receiver accepted the event
+func (r Result) Succeeded() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// ValidEvent Whether an event type is known
+func ValidEvent(event string) bool {
+	for _, known := range Events {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff Wait before the next attempt after a number of failed attempts
+func Backoff(attempts int) time.Duration {
+	wait := firstBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// NewSecret Random signing secret for an endpoint
+func NewSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
+
+// Sign Signature header value for a body sent at a time
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Client Sends webhook requests
+type Client struct {
+	HTTP *http.Client
+}
+
+// NewClient Client with a request timeout that only connects to public addresses
+func NewClient(timeout time.Duration) *Client {
+	return &Client{HTTP: safehttp.NewClient(timeout)}
+}
+
+// Deliver POST a signed envelope to an endpoint. Transport errors are returned as errors;
+// any HTTP response (including non-2xx) is returned in the result.
+func (c *Client) Deliver(ctx context.Context, url, secret string, envelope Envelope) (Result, error) {
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "reservation-platform-webhooks")
+	req.Header.Set(HeaderEvent, envelope.Type)
+	req.Header.Set(HeaderEventID, envelope.ID)
+	req.Header.Set(HeaderSignature, Sign(secret, time.Now(), body))
+
+	started := time.Now()
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return Result{Duration: time.Since(started)}, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+	return Result{StatusCode: resp.StatusCode, Duration: time.Since(started)}, nil
+}
+
+// EventID Public ID of an outbox event
+func EventID(id uint) string {
+	return fmt.Sprintf("evt_%d", id)
+}
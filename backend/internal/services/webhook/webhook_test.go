@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	timestamp := time.Unix(1710000000, 0)
+
+	want := "t=1710000000,v1=9aa6c76983dca6e776444eb182125d8bccacdda762f1172107a4a7a3ab4fca1e"
+	if got := Sign("whsec_test", timestamp, body); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+	if Sign("whsec_other", timestamp, body) == want {
+		t.Error("Sign() with another secret gave the same signature")
+	}
+	if Sign("whsec_test", timestamp.Add(time.Second), body) == want {
+		t.Error("Sign() at another time gave the same signature")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{11, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestValidEvent(t *testing.T) {
+	for _, event := range Events {
+		if !ValidEvent(event) {
+			t.Errorf("ValidEvent(%q) = false, want true", event)
+		}
+	}
+	if ValidEvent("reservation.deleted") {
+		t.Error(`ValidEvent("reservation.deleted") = true, want false`)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	envelope := Envelope{ID: EventID(7), Type: EventReservationCreated, CreatedAt: time.Now(), Data: json.RawMessage(`{"id":1}`)}
+
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("internal details"))
+	}))
+	defer server.Close()
+
+	// The test server is on loopback, which the default client refuses
+	client := &Client{HTTP: server.Client()}
+	result, err := client.Deliver(context.Background(), server.URL, "whsec_test", envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.StatusCode != http.StatusInternalServerError || result.Succeeded() {
+		t.Errorf("Deliver() = %+v, want a failed 500 result", result)
+	}
+	if received.Header.Get(HeaderEvent) != EventReservationCreated || received.Header.Get(HeaderEventID) != "evt_7" {
+		t.Errorf("event headers = %q, %q", received.Header.Get(HeaderEvent), received.Header.Get(HeaderEventID))
+	}
+	signature := received.Header.Get(HeaderSignature)
+	timestamp := strings.TrimPrefix(strings.Split(signature, ",")[0], "t=")
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("signature %q has no timestamp: %v", signature, err)
+	}
+	if want := Sign("whsec_test", time.Unix(unix, 0), receivedBody); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+}
+
+func TestNewClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook reached the loopback server")
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(5*time.Second).Deliver(context.Background(), server.URL, "whsec_test", Envelope{Data: json.RawMessage(`{}`)}); err == nil {
+		t.Error("Deliver() to a loopback address succeeded, want an error")
+	}
+}
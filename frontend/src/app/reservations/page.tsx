@@ -15,8 +15,8 @@ export default function ReservationsPage() {
   const fetchReservations = async () => {
     try {
       setLoading(true)
-      const data = await reservationAPI.getReservations()
-      setReservations(data)
+      const page = await reservationAPI.getReservations()
+      setReservations(page.data)
     } catch (error) {
       console.error('Failed to fetch reservations:', error)
       // サンプルデータで代替
@@ -8,24 +8,39 @@ export default function SalonsPage() {
   const [salons, setSalons] = useState<Salon[]>([])
   const [loading, setLoading] = useState(true)
   const [search, setSearch] = useState('')
-  const [currentPage, setCurrentPage] = useState(1)
+  const [query, setQuery] = useState('')
+  const [nextCursor, setNextCursor] = useState<string | null>(null)
+  const [hasMore, setHasMore] = useState(false)
+  const [loadingMore, setLoadingMore] = useState(false)
 
   useEffect(() => {
     fetchSalons()
-  }, [currentPage, search])
+  }, [query])
 
-  const fetchSalons = async () => {
+  // Without a cursor the list starts over; with one the next page is appended
+  const fetchSalons = async (cursor?: string) => {
     try {
-      setLoading(true)
+      if (cursor) {
+        setLoadingMore(true)
+      } else {
+        setLoading(true)
+      }
       const response = await salonAPI.getSalons({
-        page: currentPage,
+        cursor,
         limit: 12,
-        search: search || undefined,
+        search: query || undefined,
+        fields: 'id,name,address,description,phone,image_url',
       })
-      setSalons(response.data || [])
+      const page = response.data || []
+      setSalons((current) => (cursor ? [...current, ...page] : page))
+      setNextCursor(response.next_cursor)
+      setHasMore(response.has_more)
     } catch (error) {
       console.error('Failed to fetch salons:', error)
+      // Keep the pages already shown when the next one fails
+      if (cursor) return
       // Substitute with sample data
+      setHasMore(false)
       setSalons([
         {
           id: 1,
@@ -60,13 +75,17 @@ export default function SalonsPage() {
       ])
     } finally {
       setLoading(false)
+      setLoadingMore(false)
     }
   }
 
   const handleSearchSubmit = (e: React.FormEvent) => {
     e.preventDefault()
-    setCurrentPage(1)
-    fetchSalons()
+    if (search === query) {
+      fetchSalons()
+    } else {
+      setQuery(search)
+    }
   }
 
   return (
@@ -133,6 +152,18 @@ export default function SalonsPage() {
             ))}
           </div>
 
+          {hasMore && nextCursor && (
+            <div className="text-center mt-8">
+              <button
+                onClick={() => fetchSalons(nextCursor)}
+                disabled={loadingMore}
+                className="btn-secondary disabled:opacity-50 disabled:cursor-not-allowed"
+              >
+                {loadingMore ? 'Loading...' : 'Load more'}
+              </button>
+            </div>
+          )}
+
           {salons.length === 0 && !loading && (
             <div className="text-center py-12">
               <p className="text-gray-500 text-lg">
@@ -1,5 +1,5 @@
 import axios from 'axios';
-import { AuthResponse, LoginRequest, RegisterRequest, Salon, Reservation } from './types';
+import { AuthResponse, LoginRequest, RegisterRequest, Salon, Reservation, Page, AvailableSlots, PointBalance, PointEntry, GiftCardBalance, NotificationPreference, CalendarFeed, SlotHold } from './types';
 
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_BASE_URL || 'http://localhost:8082/api';
 
@@ -63,7 +63,7 @@ export const authAPI = {
 
 // Beauty Salon API
 export const salonAPI = {
-  getSalons: async (params?: { page?: number; limit?: number; search?: string }) => {
+  getSalons: async (params?: { cursor?: string; limit?: number; search?: string; fields?: string; include?: string }): Promise<Page<Salon>> => {
     const response = await api.get('/salons', { params });
     return response.data;
   },
@@ -73,16 +73,34 @@ export const salonAPI = {
     return response.data;
   },
 
-  getAvailableSlots: async (salonId: number, params: { staff_id?: number; date: string }) => {
+  getAvailableSlots: async (salonId: number, params: { staff_id?: number; service_id?: number; date: string }): Promise<AvailableSlots> => {
     const response = await api.get(`/salons/${salonId}/slots`, { params });
     return response.data;
   },
+
+  // Pushes the slots again whenever they change; close the returned source when done
+  streamAvailableSlots: (salonId: number, params: { staff_id?: number; service_id?: number; date: string }, onSlots: (slots: AvailableSlots) => void): EventSource => {
+    const query = new URLSearchParams(Object.entries(params).filter(([, v]) => v !== undefined).map(([k, v]) => [k, String(v)]));
+    const source = new EventSource(`${API_BASE_URL}/salons/${salonId}/slots/stream?${query}`);
+    source.addEventListener('slots', (event) => onSlots(JSON.parse((event as MessageEvent).data)));
+    return source;
+  },
+
+  holdSlot: async (salonId: number, data: { staff_id: number; service_id: number; start_time: string }): Promise<SlotHold> => {
+    const response = await api.post(`/salons/${salonId}/holds`, data);
+    return response.data;
+  },
+
+  releaseHold: async (token: string) => {
+    const response = await api.delete(`/holds/${token}`);
+    return response.data;
+  },
 };
 
 // Reservation API
 export const reservationAPI = {
-  getReservations: async (): Promise<Reservation[]> => {
-    const response = await api.get('/reservations');
+  getReservations: async (params?: { cursor?: string; limit?: number }): Promise<Page<Reservation>> => {
+    const response = await api.get('/reservations', { params });
     return response.data;
   },
 
@@ -105,6 +123,58 @@ export const reservationAPI = {
     const response = await api.delete(`/reservations/${id}`);
     return response.data;
   },
+
+  downloadICS: async (id: number): Promise<Blob> => {
+    const response = await api.get(`/reservations/${id}/ics`, { responseType: 'blob' });
+    return response.data;
+  },
+};
+
+// Calendar subscription API
+export const calendarAPI = {
+  getFeed: async (): Promise<CalendarFeed> => {
+    const response = await api.get('/calendar/feed');
+    return response.data;
+  },
+
+  resetFeed: async (): Promise<CalendarFeed> => {
+    const response = await api.post('/calendar/feed/reset');
+    return response.data;
+  },
+};
+
+// Loyalty points API
+export const pointsAPI = {
+  getBalance: async (): Promise<PointBalance> => {
+    const response = await api.get('/points');
+    return response.data;
+  },
+
+  getHistory: async (params?: { cursor?: string; limit?: number }): Promise<Page<PointEntry>> => {
+    const response = await api.get('/points/history', { params });
+    return response.data;
+  },
+};
+
+// Gift card API
+export const giftCardAPI = {
+  getBalance: async (code: string): Promise<GiftCardBalance> => {
+    const response = await api.get(`/gift-cards/${encodeURIComponent(code)}`);
+    return response.data;
+  },
+};
+
+// Notification API
+export const notificationAPI = {
+  getPreferences: async (): Promise<NotificationPreference> => {
+    const response = await api.get('/notifications/preferences');
+    return response.data;
+  },
+
+  updatePreferences: async (data: Omit<NotificationPreference, 'id' | 'user_id'>): Promise<NotificationPreference> => {
+    const response = await api.put('/notifications/preferences', data);
+    return response.data;
+  },
 };
 
 export default api;
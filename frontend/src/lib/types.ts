@@ -1,6 +1,7 @@
 export interface Salon {
   id: number;
   name: string;
+  name_kana?: string;
   description: string;
   address: string;
   phone?: string;
@@ -10,6 +11,18 @@ export interface Salon {
   opening_hours?: Record<string, any>;
   latitude?: number;
   longitude?: number;
+  distance_km?: number;
+  buffer_before_minutes?: number;
+  buffer_after_minutes?: number;
+  payment_policy?: '' | 'deposit' | 'prepay';
+  deposit_percent?: number;
+  free_cancel_hours?: number;
+  cancel_refund_percent?: number;
+  average_rating?: number;
+  review_count?: number;
+  is_favorite?: boolean;
+  search_rank?: number;
+  highlights?: Record<string, string>;
   staff?: Staff[];
   services?: Service[];
   created_at: string;
@@ -19,12 +32,19 @@ export interface Salon {
 export interface Staff {
   id: number;
   salon_id: number;
+  user_id?: number;
+  invited_user_id?: number;
   name: string;
   description?: string;
   image_url?: string;
   specialties?: string[];
   experience_years?: number;
+  level?: '' | StaffLevel;
   working_hours?: Record<string, any>;
+  buffer_before_minutes?: number;
+  buffer_after_minutes?: number;
+  average_rating?: number;
+  review_count?: number;
   is_active: boolean;
   salon?: Salon;
   created_at: string;
@@ -38,6 +58,10 @@ export interface Service {
   description?: string;
   price: number;
   duration_minutes: number;
+  processing_offset_minutes?: number;
+  processing_minutes?: number;
+  buffer_before_minutes?: number;
+  buffer_after_minutes?: number;
   category?: string;
   is_active: boolean;
   salon?: Salon;
@@ -53,6 +77,8 @@ export interface User {
   date_of_birth?: string;
   gender?: string;
   role: 'customer' | 'admin' | 'staff';
+  email_verified_at?: string;
+  salon_id?: number;
   created_at: string;
   updated_at: string;
 }
@@ -61,14 +87,34 @@ export interface Reservation {
   id: number;
   salon_id: number;
   staff_id: number;
-  user_id: number;
+  user_id?: number;
   service_id: number;
   reservation_date: string;
   start_time: string;
   end_time: string;
-  status: 'confirmed' | 'cancelled' | 'completed';
+  processing_start_time?: string;
+  processing_end_time?: string;
+  buffer_before_minutes?: number;
+  buffer_after_minutes?: number;
+  status: 'confirmed' | 'checked_in' | 'cancelled' | 'completed' | 'no_show';
+  checked_in_at?: string;
+  completed_at?: string;
+  customer_confirmed_at?: string;
+  calendar_sequence?: number;
   notes?: string;
+  guest_name?: string;
+  guest_email?: string;
+  guest_phone?: string;
   total_price: number;
+  price_breakdown?: PriceLine[];
+  coupon_code?: string;
+  points_used?: number;
+  gift_card_code?: string;
+  gift_card_id?: number;
+  gift_card_amount?: number;
+  payment_method?: string;
+  hold_token?: string;
+  payments?: Payment[];
   salon?: Salon;
   staff?: Staff;
   user?: User;
@@ -77,6 +123,215 @@ export interface Reservation {
   updated_at: string;
 }
 
+export interface PriceLine {
+  type: 'service' | 'staff_level' | 'surcharge' | 'promotion' | 'coupon' | 'points' | 'adjustment';
+  label: string;
+  amount: number;
+  reference?: number;
+}
+
+export type StaffLevel = 'junior' | 'senior' | 'director';
+
+export interface PriceRule {
+  id: number;
+  salon_id: number;
+  name: string;
+  staff_level: '' | StaffLevel;
+  weekdays: number[] | null;
+  start_time: string;
+  end_time: string;
+  service_ids: number[] | null;
+  adjustment_type: 'percent' | 'fixed';
+  adjustment_value: number;
+  is_active: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SlotOption {
+  start_time: string;
+  staff_id: number;
+  staff_name: string;
+  level: StaffLevel;
+  price: number;
+  price_is_estimate: boolean;
+  price_breakdown: PriceLine[];
+}
+
+export interface AvailableSlots {
+  slots: string[];
+  options?: SlotOption[];
+}
+
+export interface SlotHold {
+  id: number;
+  token: string;
+  user_id: number;
+  salon_id: number;
+  staff_id: number;
+  service_id: number;
+  start_time: string;
+  end_time: string;
+  expires_at: string;
+  created_at: string;
+}
+
+export interface LiveChange {
+  type: WebhookEventType | 'hold.created' | 'hold.released';
+  salon_id: number;
+  staff_id: number;
+  date: string;
+  reservation_id?: number;
+  hold_id?: number;
+  at: string;
+}
+
+export interface Promotion {
+  id: number;
+  salon_id: number;
+  name: string;
+  code: string;
+  discount_type: 'percent' | 'fixed';
+  discount_value: number;
+  first_visit_only: boolean;
+  weekdays: number[] | null;
+  start_time: string;
+  end_time: string;
+  service_ids: number[] | null;
+  valid_from?: string;
+  valid_until?: string;
+  max_uses?: number;
+  max_uses_per_customer?: number;
+  used_count: number;
+  is_active: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Payment {
+  id: number;
+  reservation_id: number;
+  provider: string;
+  kind: 'deposit' | 'prepay';
+  status: 'authorized' | 'captured' | 'partially_refunded' | 'refunded' | 'failed';
+  amount: number;
+  captured_amount: number;
+  refunded_amount: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PointBalance {
+  balance: number;
+  value: number;
+  yen_per_point: number;
+  earn_percent: number;
+  next_expiry?: { points: number; expires_at: string };
+}
+
+export interface PointEntry {
+  id: number;
+  user_id: number;
+  type: 'earn' | 'redeem' | 'refund' | 'expire';
+  points: number;
+  balance: number;
+  remaining?: number;
+  expires_at?: string;
+  reservation_id?: number;
+  credit_id?: number;
+  description: string;
+  created_at: string;
+}
+
+export interface GiftCardBalance {
+  code: string;
+  balance: number;
+  expires_at?: string;
+  expired: boolean;
+  salon_id: number;
+  salon_name?: string;
+}
+
+export interface NotificationPreference {
+  id?: number;
+  user_id: number;
+  email: boolean;
+  sms: boolean;
+  push: boolean;
+  locale: 'ja' | 'en';
+  push_token: string;
+}
+
+export type WebhookEventType = 'reservation.created' | 'reservation.updated' | 'reservation.cancelled' | 'reservation.completed';
+
+export interface WebhookEndpoint {
+  id: number;
+  salon_id: number;
+  url: string;
+  description: string;
+  events: WebhookEventType[] | null;
+  is_active: boolean;
+  secret?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface WebhookDelivery {
+  id: number;
+  endpoint_id: number;
+  event_id: number;
+  event_type: WebhookEventType;
+  status: 'pending' | 'succeeded' | 'failed';
+  attempts: number;
+  next_attempt_at?: string;
+  last_status_code: number;
+  last_error?: string;
+  delivered_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CalendarFeed {
+  url: string;
+  webcal_url: string;
+}
+
+export interface StaffCalendarSource {
+  id: number;
+  staff_id: number;
+  url: string;
+  synced_at?: string;
+  attempt_at?: string;
+  last_error?: string;
+  busy_blocks: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Review {
+  id: number;
+  reservation_id: number;
+  user_id: number;
+  salon_id: number;
+  staff_id: number;
+  rating: number;
+  staff_rating?: number;
+  comment?: string;
+  reply?: string;
+  replied_at?: string;
+  user?: Pick<User, 'id' | 'name'>;
+  staff?: Pick<Staff, 'id' | 'name'>;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Page<T> {
+  data: T[];
+  next_cursor: string | null;
+  has_more: boolean;
+  total?: number;
+}
+
 export interface AuthResponse {
   token: string;
   user: User;